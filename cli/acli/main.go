@@ -63,9 +63,6 @@ func main() {
 		client.LineBreak,
 		version.Cmd,
 		client.NewCompletionCmd(rootCmd, true),
-		// TODO: Add a command that fetches the list of registered voters and the credential
-		//  polynomial, re-calculates the polynomial from the list and compares the result to the
-		//  retrieved polynomial.
 	)
 
 	executor := cli.PrepareMainCmd(rootCmd, "ElectionAdmin", ElectionAdminCliHomeDir)
@@ -8,6 +8,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	"github.com/csmuller/up-voting-system/crypto"
 	"github.com/csmuller/up-voting-system/pbb"
+	pbbcli "github.com/csmuller/up-voting-system/pbb/client/cli"
 	tlog "github.com/tendermint/tendermint/libs/log"
 	"io"
 	"os"
@@ -60,6 +61,15 @@ func main() {
 		genutilcli.ValidateGenesisCmd(ctx, cdc, pbb.ModuleManager),
 		// AddGenesisAccountCmd allows users to add accounts to the genesis file
 		genaccscli.AddGenesisAccountCmd(ctx, cdc, NodeHomeDirectory, DefaultClientHomeDirectory),
+		// Commands to configure the election in the genesis file
+		pbbcli.GetCmdSetElectionSchedule(ctx, cdc, NodeHomeDirectory),
+		pbbcli.GetCmdSetGeneratorSeed(ctx, cdc, NodeHomeDirectory),
+		pbbcli.GetCmdSetElectionID(ctx, cdc, NodeHomeDirectory),
+		pbbcli.GetCmdAddAdmin(ctx, cdc, NodeHomeDirectory),
+		pbbcli.GetCmdAddToGenesisRoll(ctx, cdc, NodeHomeDirectory),
+		pbbcli.GetCmdSetSecurityPreset(ctx, cdc, NodeHomeDirectory),
+		pbbcli.GetCmdGenerateParameters(),
+		pbbcli.GetCmdImportParameters(ctx, cdc, NodeHomeDirectory),
 	)
 
 	server.AddCommands(ctx, cdc, rootCmd, newApp, exportAppStateAndTMValidators)
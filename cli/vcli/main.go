@@ -59,8 +59,6 @@ func main() {
 		version.Cmd,
 		client.NewCompletionCmd(rootCmd, true),
 		client.LineBreak,
-		// TODO: Add a command that fetches the credential polynomial and verifies if a voters
-		//  public credential is included in it, i.e. P(u) = 0.
 	)
 
 	executor := cli.PrepareMainCmd(rootCmd, "Voter", VoterCliHomeDir)
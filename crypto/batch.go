@@ -0,0 +1,164 @@
+package crypto
+
+import (
+	"math/big"
+)
+
+// This file implements the batch verification of proofs. The verification of every proof in this
+// package boils down to a number of equations between products of powers of group elements. The
+// equations of many proofs are combined into a single product by raising each equation to a small
+// random exponent (small-exponent test, Bellare et al., "Fast Batch Verification for Modular
+// Exponentiation and Digital Signatures"). If all equations hold, so does the combination. If one
+// of them does not hold, the combination fails with overwhelming probability.
+
+// batchSecurityBits is the bit length of the random exponents of the small-exponent test. A batch
+// containing an equation that does not hold is accepted with a probability of at most 2^-80.
+const batchSecurityBits = 80
+
+// equation represents the verification equation left = right in a group.
+type equation struct {
+	group Group
+	left  product
+	right product
+}
+
+// newEquation creates the equation left = right in the given group.
+func newEquation(g Group) *equation {
+	return &equation{group: g}
+}
+
+// holds checks if this equation holds.
+func (e *equation) holds() bool {
+	return e.left.eval(e.group).Cmp(e.right.eval(e.group)) == 0
+}
+
+// Equations are the verification equations of a proof. A proof is valid if all of its equations
+// hold. They are created by the proof systems and can be verified at once with a BatchVerifier.
+type Equations []*equation
+
+// Hold checks if all of the equations hold.
+func (eqs Equations) Hold() bool {
+	for _, e := range eqs {
+		if !e.holds() {
+			return false
+		}
+	}
+	return true
+}
+
+// BatchVerifier verifies the equations of many items at once. An item is anything that is valid
+// if all of its equations hold, e.g. a ballot with its three proofs. If the combined equations do
+// not hold, the items are bisected to find the invalid ones.
+type BatchVerifier struct {
+	items []Equations
+}
+
+// NewBatchVerifier creates a new and empty batch verifier.
+func NewBatchVerifier() *BatchVerifier {
+	return &BatchVerifier{}
+}
+
+// Add adds an item consisting of the given equations, e.g. the equations of all proofs of a
+// ballot, to the batch. Returns the index of the item in the batch.
+func (b *BatchVerifier) Add(eqs ...Equations) int {
+	var item Equations
+	for _, e := range eqs {
+		item = append(item, e...)
+	}
+	b.items = append(b.items, item)
+	return len(b.items) - 1
+}
+
+// Len returns the number of items in the batch.
+func (b *BatchVerifier) Len() int {
+	return len(b.items)
+}
+
+// Verify verifies all items of the batch and returns the indices of the invalid items in
+// ascending order. If all items are valid, the returned slice is empty. The items are verified
+// in a single combined check. Only if that check fails, the batch is split in halves which are
+// checked recursively.
+func (b *BatchVerifier) Verify() []int {
+	indices := make([]int, len(b.items))
+	for i := range indices {
+		indices[i] = i
+	}
+	return b.bisect(indices)
+}
+
+// bisect returns the invalid items among the items with the given indices.
+func (b *BatchVerifier) bisect(indices []int) []int {
+	if len(indices) == 0 || b.holds(indices) {
+		return nil
+	}
+	if len(indices) == 1 {
+		return indices
+	}
+	mid := len(indices) / 2
+	return append(b.bisect(indices[:mid]), b.bisect(indices[mid:])...)
+}
+
+// holds checks the combination of the equations of the items with the given indices. Every
+// equation left = right is raised to a random exponent r and all equations of a group are combined
+// into the single check prod(left^r * right^(-r)) = 1. Powers of the same base are merged such
+// that the generators of the commitment schemes are exponentiated only once per group.
+func (b *BatchVerifier) holds(indices []int) bool {
+	bound := new(big.Int).Lsh(big.NewInt(1), batchSecurityBits)
+	combined := make(map[Group]*combination)
+	var groups []Group
+	for _, idx := range indices {
+		for _, e := range b.items[idx] {
+			c, ok := combined[e.group]
+			if !ok {
+				c = newCombination(e.group)
+				combined[e.group] = c
+				groups = append(groups, e.group)
+			}
+			r := RandInt(bound)
+			c.add(e.left, r)
+			c.add(e.right, new(big.Int).Neg(r))
+		}
+	}
+	for _, g := range groups {
+		if !combined[g].isIdentity() {
+			return false
+		}
+	}
+	return true
+}
+
+// combination is the product of randomized equations in a group. The exponents of equal bases are
+// summed up modulo the group order.
+type combination struct {
+	group Group
+	zq    ZModPrime
+	index map[string]int
+	terms product
+}
+
+func newCombination(g Group) *combination {
+	return &combination{group: g, zq: g.ZModOrder(), index: make(map[string]int)}
+}
+
+// add multiplies the combination with the given product raised to r.
+func (c *combination) add(p product, r *big.Int) {
+	for i, base := range p.bases {
+		exp := c.zq.Mul(p.exps[i], r)
+		key := string(base.Bytes())
+		if j, ok := c.index[key]; ok {
+			c.terms.exps[j] = c.zq.Add(c.terms.exps[j], exp)
+			continue
+		}
+		c.index[key] = len(c.terms.bases)
+		if p.fixed[i] {
+			c.terms.mulFixed(base, exp)
+		} else {
+			c.terms.mul(base, exp)
+		}
+	}
+}
+
+// isIdentity checks if the combination is equal to the identity element of the group.
+func (c *combination) isIdentity() bool {
+	return c.terms.eval(c.group).Cmp(c.group.IdentityElement()) == 0
+}
@@ -0,0 +1,93 @@
+package crypto
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// batchTestSetup creates an election with the given number of voters and a valid ballot for each
+// of them.
+func batchTestSetup(tb testing.TB, voters int) (testElection, []testBallot) {
+	e := newTestElection(tb, voters, nil)
+	ballots := make([]testBallot, voters)
+	for i, voter := range e.voters {
+		ballots[i] = e.newBallot(voter, "yes")
+	}
+	return e, ballots
+}
+
+// batchVerify adds the proofs of the given ballots to a batch verifier and returns the indices of
+// the invalid ballots.
+func batchVerify(tb testing.TB, e testElection, ballots []testBallot) []int {
+	batch := NewBatchVerifier()
+	for _, b := range ballots {
+		e1, err := e.ps1.Equations(b.proof1, b.commToU, b.vote)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		e2, err := e.ps2.Equations(b.proof2, b.commToU, b.commToAandB, b.vote)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		e3, err := e.ps3.Equations(b.proof3, b.commToAandB, b.uHat, b.vote)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		batch.Add(e1, e2, e3)
+	}
+	if batch.Len() != len(ballots) {
+		tb.Errorf("batch must contain %d items", len(ballots))
+	}
+	return batch.Verify()
+}
+
+func TestBatchVerifier(t *testing.T) {
+	e, ballots := batchTestSetup(t, 6)
+
+	if invalid := batchVerify(t, e, ballots); len(invalid) != 0 {
+		t.Errorf("all ballots must be valid but %v are not", invalid)
+	}
+
+	// Tamper with one proof of each of three ballots.
+	ballots[1].vote = "no"
+	ballots[4].proof2.ZR = new(big.Int).Add(ballots[4].proof2.ZR, big.NewInt(1))
+	ballots[5].uHat = ballots[0].uHat
+	invalid := batchVerify(t, e, ballots)
+	if !reflect.DeepEqual(invalid, []int{1, 4, 5}) {
+		t.Errorf("ballots [1 4 5] must be invalid but %v are", invalid)
+	}
+	for i, b := range ballots {
+		v, _ := e.verify(b)
+		valid := i != 1 && i != 4 && i != 5
+		if v != valid {
+			t.Errorf("individual verification of ballot %d must agree with the batch", i)
+		}
+	}
+
+	if invalid := NewBatchVerifier().Verify(); len(invalid) != 0 {
+		t.Error("an empty batch must be valid")
+	}
+}
+
+func BenchmarkBatchVerification(b *testing.B) {
+	e, ballots := batchTestSetup(b, 16)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if invalid := batchVerify(b, e, ballots); len(invalid) != 0 {
+			b.Fatal("all ballots must be valid")
+		}
+	}
+}
+
+func BenchmarkIndividualVerification(b *testing.B) {
+	e, ballots := batchTestSetup(b, 16)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, ballot := range ballots {
+			if v, _ := e.verify(ballot); !v {
+				b.Fatal("all ballots must be valid")
+			}
+		}
+	}
+}
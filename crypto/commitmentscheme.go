@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/tendermint/go-amino"
 	"math/big"
@@ -13,14 +14,14 @@ import (
 // The scheme can be used in a generalized manner, i.e. one can commit to multiple messages in one
 // commitment. Thus, a instance of the scheme can have multiple message generators.
 type PedersenCommitmentScheme struct {
-	G  GStarModPrime // cyclic, multiplicative group cyclic group of the generators
-	Hr *big.Int      // randomization generator, h_0
-	Hm []*big.Int    // message generators, h_1 to h_n
+	G  Group      // cyclic group of prime order of the generators
+	Hr *big.Int   // randomization generator, h_0
+	Hm []*big.Int // message generators, h_1 to h_n
 }
 
 // NewPedersenCommitmentScheme creates a new instance of the scheme. The instance is based on the
 // given group and generators which must be generators of the group.
-func NewPedersenCommitmentScheme(g GStarModPrime, hr *big.Int,
+func NewPedersenCommitmentScheme(g Group, hr *big.Int,
 	hm []*big.Int) PedersenCommitmentScheme {
 
 	return PedersenCommitmentScheme{
@@ -30,30 +31,96 @@ func NewPedersenCommitmentScheme(g GStarModPrime, hr *big.Int,
 	}
 }
 
-// Commit creates a commitment to the given messages msgs with the given randomness r.
-func (s *PedersenCommitmentScheme) Commit(r *big.Int, msgs ...*big.Int) *big.Int {
-	if len(msgs) != len(s.Hm) {
-		panic("The number of messages is not equal to the number of message generators in this" +
-			" commitment scheme.")
+// NewPedersenCommitmentSchemeFromSeed creates a new instance of the scheme in the given group with
+// the given number of message generators. The generators are derived from the public seed such
+// that anyone can re-derive them. The randomization generator is derived with index 0 and the
+// message generators with indices 1 to n.
+func NewPedersenCommitmentSchemeFromSeed(g Group, seed string,
+	nrOfMessages int) PedersenCommitmentScheme {
+
+	hm := make([]*big.Int, nrOfMessages)
+	for i := range hm {
+		hm[i] = g.GeneratorFromSeed(seed, uint32(i+1))
 	}
-	for _, msg := range msgs {
-		if msg.Cmp(s.G.Order) == 1 || msg.Cmp(big.NewInt(0)) == -1 {
-			panic(fmt.Sprintf("The message is not in Z_q, where q is %s", s.G.Order.String()))
+	return NewPedersenCommitmentScheme(g, g.GeneratorFromSeed(seed, 0), hm)
+}
+
+// IsDerivedFromSeed checks if all generators of this scheme are the ones derived from the given
+// seed by NewPedersenCommitmentSchemeFromSeed.
+func (s *PedersenCommitmentScheme) IsDerivedFromSeed(seed string) bool {
+	derived := NewPedersenCommitmentSchemeFromSeed(s.G, seed, len(s.Hm))
+	if s.Hr == nil || s.Hr.Cmp(derived.Hr) != 0 {
+		return false
+	}
+	for i, h := range s.Hm {
+		if h == nil || h.Cmp(derived.Hm[i]) != 0 {
+			return false
 		}
 	}
-	if r.Cmp(s.G.Order) == 1 || r.Cmp(big.NewInt(0)) == -1 {
-		panic(fmt.Sprintf("The random value is not in Z_q, where q is %s", s.G.Order.String()))
+	return true
+}
+
+// Validate checks that the group of this scheme is valid and that all generators are generators of
+// the group.
+func (s *PedersenCommitmentScheme) Validate() error {
+	if s.G == nil {
+		return errors.New("the group of the scheme is not set")
 	}
+	if err := s.G.Validate(); err != nil {
+		return err
+	}
+	if !s.G.IsGenerator(s.Hr) {
+		return errors.New("h_r is not a generator of the group")
+	}
+	for i, h := range s.Hm {
+		if !s.G.IsGenerator(h) {
+			return fmt.Errorf("h_%d is not a generator of the group", i+1)
+		}
+	}
+	return nil
+}
 
-	product := s.G.Exp(s.Hr, r)
+// Commit creates a commitment to the given messages msgs with the given randomness r. Returns an
+// error if the number of messages does not match the number of message generators or if the
+// randomness or a message is not in Z_q, where q is the order of the scheme's group.
+func (s *PedersenCommitmentScheme) Commit(r *big.Int, msgs ...*big.Int) (*big.Int, error) {
+	if len(msgs) != len(s.Hm) {
+		return nil, fmt.Errorf("the number of messages (%d) is not equal to the number of "+
+			"message generators (%d) in this commitment scheme", len(msgs), len(s.Hm))
+	}
+	zq := s.G.ZModOrder()
 	for i, msg := range msgs {
-		if msg.Cmp(s.G.Order) == 1 || msg.Cmp(big.NewInt(0)) == -1 {
-			panic(fmt.Sprintf("The message is not in Z_q, where q is %s", s.G.Order.String()))
+		if msg == nil || !zq.Contains(msg) {
+			return nil, fmt.Errorf("message %d is not in Z_q, where q is %s", i+1, zq.Modulus)
 		}
-		t := s.G.Exp(s.Hm[i], msg)
-		product = s.G.Mul(product, t)
 	}
-	return product
+	if r == nil || !zq.Contains(r) {
+		return nil, fmt.Errorf("the random value is not in Z_q, where q is %s", zq.Modulus)
+	}
+	return s.commit(r, msgs...), nil
+}
+
+// commit creates a commitment like Commit but without checking the inputs. It is used by the
+// proof systems with inputs that are known to be in the required ranges.
+func (s *PedersenCommitmentScheme) commit(r *big.Int, msgs ...*big.Int) *big.Int {
+	var p product
+	s.commitTerms(&p, r, msgs...)
+	return p.eval(s.G)
+}
+
+// commitTerms multiplies the given product with the powers of the generators a commitment to the
+// given messages with randomness r consists of.
+func (s *PedersenCommitmentScheme) commitTerms(p *product, r *big.Int, msgs ...*big.Int) {
+	p.mulFixed(s.Hr, r)
+	s.messageTerms(p, msgs...)
+}
+
+// messageTerms multiplies the given product with the powers h_1^m_1 * ... * h_n^m_n of the
+// message generators.
+func (s *PedersenCommitmentScheme) messageTerms(p *product, msgs ...*big.Int) {
+	for i, msg := range msgs {
+		p.mulFixed(s.Hm[i], msg)
+	}
 }
 
 // String returns a string representation of this scheme.
@@ -68,42 +135,31 @@ func (s *PedersenCommitmentScheme) String() string {
 }
 
 // pedersenCommitmentSchemeDTO is used for Tendermint serialization and deserialization of the
-// PedersenCommitmentScheme type.
+// PedersenCommitmentScheme type. Schemes in a Schnorr group carry the group's modulus and order,
+// schemes in an elliptic curve group the curve's name.
 type pedersenCommitmentSchemeDTO struct {
 	GStarModPr GStarModPrime `json:"g"`
 	Hr         Int           `json:"hr"`
 	Hm         []Int         `json:"hm"`
+	Curve      string        `json:"curve,omitempty"`
 }
 
 func (s PedersenCommitmentScheme) MarshalAmino() (string, error) {
-	hm := make([]Int, len(s.Hm))
-	for i, hi := range s.Hm {
-		hm[i] = NewInt(hi)
-	}
-	dto := pedersenCommitmentSchemeDTO{GStarModPr: s.G, Hr: NewInt(s.Hr), Hm: hm}
-	return string(amino.MustMarshalBinaryBare(dto)), nil
+	bz, err := amino.MarshalBinaryBare(s.wrapInDTO())
+	return string(bz), err
 }
 
 func (s *PedersenCommitmentScheme) UnmarshalAmino(bytes []byte) error {
 	var dto pedersenCommitmentSchemeDTO
-	amino.MustUnmarshalBinaryBare(bytes, &dto)
-	s.Hm = make([]*big.Int, len(dto.Hm))
-	for i, hi := range dto.Hm {
-		s.Hm[i] = hi.BigInt()
+	if err := amino.UnmarshalBinaryBare(bytes, &dto); err != nil {
+		return err
 	}
-	s.Hr = dto.Hr.BigInt()
-	s.G = dto.GStarModPr
-	return nil
+	return s.unwrapDTO(dto)
 }
 
 // MarshalJSON serializes this big integer to JSON format
 func (s PedersenCommitmentScheme) MarshalJSON() ([]byte, error) {
-	hm := make([]Int, len(s.Hm))
-	for i, hi := range s.Hm {
-		hm[i] = NewInt(hi)
-	}
-	dto := pedersenCommitmentSchemeDTO{GStarModPr: s.G, Hr: NewInt(s.Hr), Hm: hm}
-	return json.Marshal(dto)
+	return json.Marshal(s.wrapInDTO())
 }
 
 func (s *PedersenCommitmentScheme) UnmarshalJSON(bytes []byte) error {
@@ -112,11 +168,39 @@ func (s *PedersenCommitmentScheme) UnmarshalJSON(bytes []byte) error {
 	if err != nil {
 		return err
 	}
+	return s.unwrapDTO(dto)
+}
+
+func (s PedersenCommitmentScheme) wrapInDTO() pedersenCommitmentSchemeDTO {
+	hm := make([]Int, len(s.Hm))
+	for i, hi := range s.Hm {
+		hm[i] = NewInt(hi)
+	}
+	dto := pedersenCommitmentSchemeDTO{Hr: NewInt(s.Hr), Hm: hm}
+	switch g := s.G.(type) {
+	case *GStarModPrime:
+		dto.GStarModPr = *g
+	case *ECGroup:
+		dto.Curve = g.Name
+	}
+	return dto
+}
+
+func (s *PedersenCommitmentScheme) unwrapDTO(dto pedersenCommitmentSchemeDTO) error {
+	if dto.Curve != "" {
+		g, err := NewECGroup(dto.Curve)
+		if err != nil {
+			return err
+		}
+		s.G = g
+	} else {
+		g := dto.GStarModPr
+		s.G = &g
+	}
 	s.Hm = make([]*big.Int, len(dto.Hm))
 	for i, hi := range dto.Hm {
 		s.Hm[i] = hi.BigInt()
 	}
 	s.Hr = dto.Hr.BigInt()
-	s.G = dto.GStarModPr
 	return nil
 }
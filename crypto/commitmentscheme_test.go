@@ -0,0 +1,45 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCommitRejectsInvalidInput(t *testing.T) {
+	_, commQ := newTestSchemes()
+	q := commQ.G.ZModOrder().Modulus
+
+	r := commQ.G.ZModOrder().RandomElement()
+	if _, err := commQ.Commit(r, big.NewInt(1), big.NewInt(2)); err != nil {
+		t.Error(err)
+	}
+	if _, err := commQ.Commit(r, big.NewInt(1)); err == nil {
+		t.Error("commitment with too few messages must fail")
+	}
+	if _, err := commQ.Commit(r, big.NewInt(1), q); err == nil {
+		t.Error("commitment to a message outside of Z_q must fail")
+	}
+	if _, err := commQ.Commit(nil, big.NewInt(1), big.NewInt(2)); err == nil {
+		t.Error("commitment without randomness must fail")
+	}
+}
+
+func TestUnmarshalAminoRejectsGarbage(t *testing.T) {
+	garbage := []byte{0xff, 0xff, 0xff, 0xff, 0x0f}
+	var scheme PedersenCommitmentScheme
+	if scheme.UnmarshalAmino(garbage) == nil {
+		t.Error("decoding garbage into a commitment scheme must fail")
+	}
+	var poly Polynomial
+	if poly.UnmarshalAmino(garbage) == nil {
+		t.Error("decoding garbage into a polynomial must fail")
+	}
+	var g GStarModPrime
+	if g.UnmarshalAmino(garbage) == nil {
+		t.Error("decoding garbage into a group must fail")
+	}
+	var i Int
+	if !i.IsZero() || i.IsNegative() {
+		t.Error("an unset integer must be zero and not negative")
+	}
+}
@@ -0,0 +1,29 @@
+package crypto
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestConcurrentProofSystems generates and verifies many ballots in parallel with shared proof
+// system instances. Run it with the race detector (go test -race) to detect shared mutable state.
+func TestConcurrentProofSystems(t *testing.T) {
+	e := newTestElection(t, 8, []byte("ctx"))
+
+	var wg sync.WaitGroup
+	valid := make([]bool, len(e.voters))
+	for i, voter := range e.voters {
+		wg.Add(1)
+		go func(i int, voter Voter) {
+			defer wg.Done()
+			v, err := e.verify(e.newBallot(voter, "yes"))
+			valid[i] = err == nil && v
+		}(i, voter)
+	}
+	wg.Wait()
+	for i, v := range valid {
+		if !v {
+			t.Errorf("ballot %d generated concurrently must verify", i)
+		}
+	}
+}
@@ -2,8 +2,9 @@ package crypto
 
 import "C"
 import (
-	"crypto/sha256"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/tendermint/go-amino"
 	"math/big"
 	"time"
@@ -14,32 +15,56 @@ import (
 // It is based on the paper "Proof-of-Knowledge of Representation of Committed Value and Its
 // Applications" by Man Ho Au et al.
 // The class name is due to it being a generalization of double discrete log proofs.
+// Generate and Verify do not modify the proof system, so one instance can be shared by goroutines.
 type DoubleDiscreteLogProofSystem struct {
 	CommSchemeInGp PedersenCommitmentScheme // The commitment scheme used for the committed value
 	CommSchemeInGq PedersenCommitmentScheme // The commitment scheme used for the representation.
 	SecurityParam  int                      // Security parameter determining the security level of the proof system.
+	Context        []byte                   // Absorbed into every challenge, e.g. to bind proofs to an election.
 	zp             ZModPrime
 	zq             ZModPrime
-	gp             GStarModPrime
-	gq             GStarModPrime
+	gp             Group
+	gq             *GStarModPrime
 }
 
 // NewDoubleDiscreteLogProofSystem creates a new instance of the proof system with the given
-// commitment schemes and security parameter.
+// commitment schemes and security parameter. G_p can be any group of prime order p but G_q must be
+// a Schnorr group with modulus p. Returns an error if the order p of G_p does not satisfy
+// p = bq + 1 for the order q of G_q, if G_q is not a subgroup of Z*_p, if p is not bigger than 2^k
+// for the security parameter k or if the schemes do not have one (G_p) and two (G_q) message
+// generators.
 func NewDoubleDiscreteLogProofSystem(commSchemeInGp PedersenCommitmentScheme,
-	commSchemeInGq PedersenCommitmentScheme, securityParam int) DoubleDiscreteLogProofSystem {
+	commSchemeInGq PedersenCommitmentScheme,
+	securityParam int) (DoubleDiscreteLogProofSystem, error) {
 
-	p := commSchemeInGp.G.Order
-	q := commSchemeInGq.G.Order
-	// Check if p = rq + 1
-	if new(big.Int).GCD(nil, nil, new(big.Int).Sub(p, big.NewInt(1)), q).Cmp(q) != 0 {
-		panic("Order p of cyclic group G_p must satisfy p = bq + 1 for some r and order q of" +
-			" cyclic group G_q.")
+	if commSchemeInGp.G == nil || commSchemeInGq.G == nil {
+		return DoubleDiscreteLogProofSystem{}, errors.New("the groups G_p and G_q must be set")
+	}
+	p := commSchemeInGp.G.ZModOrder().Modulus
+	q := commSchemeInGq.G.ZModOrder().Modulus
+	if p == nil || q == nil || q.Sign() <= 0 {
+		return DoubleDiscreteLogProofSystem{}, errors.New("the orders of G_p and G_q must be set")
+	}
+	// The elements of G_q are committed to in G_p, i.e. they are used as exponents in G_p and
+	// multiplied in Z_p. G_q must therefore be a subgroup of Z*_p.
+	gq, ok := commSchemeInGq.G.(*GStarModPrime)
+	if !ok || gq.Modulus == nil || gq.Modulus.Cmp(p) != 0 {
+		return DoubleDiscreteLogProofSystem{}, errors.New("G_q must be a Schnorr group with " +
+			"the order p of G_p as modulus")
+	}
+	// Check if p = bq + 1
+	if new(big.Int).Mod(new(big.Int).Sub(p, big.NewInt(1)), q).Sign() != 0 {
+		return DoubleDiscreteLogProofSystem{}, errors.New("order p of cyclic group G_p must " +
+			"satisfy p = bq + 1 for some b and order q of cyclic group G_q")
 	}
 	// Check if 2^k < p, where k is the security parameter.
-	if p.Cmp(new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(securityParam)), nil)) < 1 {
-		panic("Order p of cyclic group G_p must be bigger than 2^k where k is the security" +
-			" parameter.")
+	if securityParam <= 0 || p.BitLen() <= securityParam {
+		return DoubleDiscreteLogProofSystem{}, errors.New("order p of cyclic group G_p must be " +
+			"bigger than 2^k where k > 0 is the security parameter")
+	}
+	if len(commSchemeInGp.Hm) != 1 || len(commSchemeInGq.Hm) != 2 {
+		return DoubleDiscreteLogProofSystem{}, errors.New("the commitment scheme in G_p must " +
+			"have one and the scheme in G_q two message generators")
 	}
 
 	return DoubleDiscreteLogProofSystem{
@@ -49,8 +74,8 @@ func NewDoubleDiscreteLogProofSystem(commSchemeInGp PedersenCommitmentScheme,
 		zp:             commSchemeInGp.G.ZModOrder(),
 		zq:             commSchemeInGq.G.ZModOrder(),
 		gp:             commSchemeInGp.G,
-		gq:             commSchemeInGq.G,
-	}
+		gq:             gq,
+	}, nil
 }
 
 // DdLogProof represents a proof transcript for a proof of known representation of a
@@ -79,7 +104,7 @@ func (ps *DoubleDiscreteLogProofSystem) Generate(voter Voter, commToU *big.Int,
 	// 1. Create commitment
 	rhoX := ps.zp.RandomElement()
 	rhoR := ps.zp.RandomElement()
-	t := ps.CommSchemeInGp.Commit(rhoR, rhoX)
+	t := ps.CommSchemeInGp.commit(rhoR, rhoX)
 
 	rhoMArr := make([][]*big.Int, ps.SecurityParam)
 	for i := range rhoMArr {
@@ -93,14 +118,12 @@ func (ps *DoubleDiscreteLogProofSystem) Generate(voter Voter, commToU *big.Int,
 	for i := 0; i < ps.SecurityParam; i++ {
 		rhoSArr[i] = ps.zq.RandomElement()
 		rhoRArr[i] = ps.zp.RandomElement()
-		hProduct := big.NewInt(1)
 		for j := 0; j < len(ps.CommSchemeInGq.Hm); j++ {
 			rhoMArr[i][j] = ps.zq.RandomElement()
-			hExp := ps.gq.Exp(ps.CommSchemeInGq.Hm[j], rhoMArr[i][j])
-			hProduct = ps.gq.Mul(hProduct, hExp)
 		}
-		t1Arr[i] = ps.CommSchemeInGp.Commit(rhoRArr[i], hProduct)
-		t2Arr[i] = ps.CommSchemeInGq.Commit(rhoSArr[i], rhoMArr[i]...)
+		hProduct := ps.hProduct(rhoMArr[i])
+		t1Arr[i] = ps.CommSchemeInGp.commit(rhoRArr[i], hProduct)
+		t2Arr[i] = ps.CommSchemeInGq.commit(rhoSArr[i], rhoMArr[i]...)
 	}
 
 	// 2. Create challenge
@@ -134,25 +157,84 @@ func (ps *DoubleDiscreteLogProofSystem) Generate(voter Voter, commToU *big.Int,
 		}
 		zMArr[i] = zMiArr
 		zSArr[i] = ps.zq.Add(rhoSArr[i], ps.zq.AdditiveInvert(ps.zq.Mul(s, bit)))
-		hProduct := big.NewInt(1)
-		for j := 0; j < len(ps.CommSchemeInGq.Hm); j++ {
-			hExp := ps.gq.Exp(ps.CommSchemeInGq.Hm[j], zMArr[i][j])
-			hProduct = ps.gq.Mul(hProduct, hExp)
-		}
+		hProduct := ps.hProduct(zMArr[i])
 		zRArr[i] = ps.zp.Add(rhoRArr[i], ps.zp.AdditiveInvert(ps.zp.Mul(ps.zp.Mul(bit, hProduct), r)))
 	}
 
 	return DdLogProof{t, t1Arr, t2Arr, zX, zR, zMArr, zSArr, zRArr}
 }
 
+// ValidateProof checks that the arrays of the given transcript have a length equal to the security
+// parameter, that t and all elements of t1 are elements of G_p, that all elements of t2 are
+// elements of G_q and that all responses are elements of Z_p or Z_q respectively.
+func (ps *DoubleDiscreteLogProofSystem) ValidateProof(proof DdLogProof) error {
+	k := ps.SecurityParam
+	if err := checkElement(ps.gp, "t", proof.T); err != nil {
+		return err
+	}
+	if err := (transcriptArray{"t1", proof.T1Arr, k}).checkElements(ps.gp); err != nil {
+		return err
+	}
+	if err := (transcriptArray{"t2", proof.T2Arr, k}).checkElements(ps.gq); err != nil {
+		return err
+	}
+	if err := checkRingElement(ps.zp, "z_x", proof.ZX); err != nil {
+		return err
+	}
+	if err := checkRingElement(ps.zp, "z_r", proof.ZR); err != nil {
+		return err
+	}
+	if err := (transcriptArray{"z_r_arr", proof.ZRArr, k}).checkRingElements(ps.zp); err != nil {
+		return err
+	}
+	if err := (transcriptArray{"z_s_arr", proof.ZSArr, k}).checkRingElements(ps.zq); err != nil {
+		return err
+	}
+	if len(proof.ZMArr) != k {
+		return fmt.Errorf("z_m_arr has length %d but must have length %d", len(proof.ZMArr), k)
+	}
+	for i, zM := range proof.ZMArr {
+		name := fmt.Sprintf("z_m_arr[%d]", i)
+		err := transcriptArray{name, zM, len(ps.CommSchemeInGq.Hm)}.checkRingElements(ps.zq)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Verify verifies a proof of known representation of committed values. Next to the proof
 // transcript, the committed value and the commitment to the representation are the input
-// parameters.
+// parameters. The transcript and the commitments are validated first. If they are malformed, an
+// error is returned and no verification takes place.
 func (ps *DoubleDiscreteLogProofSystem) Verify(proof DdLogProof, commToU *big.Int,
-	commToAandB *big.Int, vote string) bool {
+	commToAandB *big.Int, vote string) (bool, error) {
 
 	defer LogExecutionTime(time.Now(), "double discrete log proof verification")
 
+	eqs, err := ps.Equations(proof, commToU, commToAandB, vote)
+	if err != nil {
+		return false, err
+	}
+	return eqs.Hold(), nil
+}
+
+// Equations validates the given proof transcript and commitments like Verify and returns the
+// equations that hold if the proof is valid. They can be verified together with the equations of
+// other proofs with a BatchVerifier.
+func (ps *DoubleDiscreteLogProofSystem) Equations(proof DdLogProof, commToU *big.Int,
+	commToAandB *big.Int, vote string) (Equations, error) {
+
+	if err := checkElement(ps.gp, "commitment to u", commToU); err != nil {
+		return nil, err
+	}
+	if err := checkElement(ps.gq, "commitment to a and b", commToAandB); err != nil {
+		return nil, err
+	}
+	if err := ps.ValidateProof(proof); err != nil {
+		return nil, err
+	}
+
 	t := proof.T
 	t1 := proof.T1Arr
 	t2 := proof.T2Arr
@@ -166,47 +248,70 @@ func (ps *DoubleDiscreteLogProofSystem) Verify(proof DdLogProof, commToU *big.In
 	// 2. Create challenge
 	ch := ps.generateChallenge(commToU, commToAandB, proof.T, proof.T1Arr, proof.T2Arr, vote)
 
-	comm := ps.CommSchemeInGp.Commit(zR, zX)
-	v := t.Cmp(ps.gp.Mul(ps.gp.Exp(commToU, ch), comm)) == 0
+	eqs := make(Equations, 0, 2*ps.SecurityParam+1)
+	eq := newEquation(ps.gp)
+	eq.left.mulElement(t)
+	eq.right.mul(commToU, ch)
+	ps.CommSchemeInGp.commitTerms(&eq.right, zR, zX)
+	eqs = append(eqs, eq)
 
 	for i := 0; i < ps.SecurityParam; i++ {
-		bit := big.NewInt(int64(ch.Bit(i)))
+		bit := ch.Bit(i)
 		// T2
-		comm := ps.CommSchemeInGq.Commit(zSArr[i], zMArr[i]...)
-		v = v && t2[i].Cmp(ps.gq.Mul(ps.gq.Exp(commToAandB, bit), comm)) == 0
-		// T1
-		hProduct := big.NewInt(1)
-		for j := 0; j < len(ps.CommSchemeInGq.Hm); j++ {
-			hExp := ps.gq.Exp(ps.CommSchemeInGq.Hm[j], zMArr[i][j])
-			hProduct = ps.gq.Mul(hProduct, hExp)
+		eq := newEquation(ps.gq)
+		eq.left.mulElement(t2[i])
+		if bit == 1 {
+			eq.right.mulElement(commToAandB)
 		}
-		if bit.Cmp(big.NewInt(0)) == 0 {
-			v = v && t1[i].Cmp(ps.CommSchemeInGp.Commit(zRArr[i], hProduct)) == 0
+		ps.CommSchemeInGq.commitTerms(&eq.right, zSArr[i], zMArr[i]...)
+		eqs = append(eqs, eq)
+		// T1
+		hProduct := ps.hProduct(zMArr[i])
+		eq = newEquation(ps.gp)
+		eq.left.mulElement(t1[i])
+		if bit == 0 {
+			ps.CommSchemeInGp.commitTerms(&eq.right, zRArr[i], hProduct)
 		} else {
-			g := ps.CommSchemeInGp.Hr
-			temp := ps.gp.Mul(ps.gp.Exp(commToU, hProduct), ps.gp.Exp(g, zRArr[i]))
-			v = v && t1[i].Cmp(temp) == 0
+			eq.right.mul(commToU, hProduct)
+			eq.right.mul(ps.CommSchemeInGp.Hr, zRArr[i])
 		}
+		eqs = append(eqs, eq)
 	}
 
-	return v
+	return eqs, nil
+}
+
+// hProduct computes h_1^m_1 * ... * h_n^m_n in G_q, where h_i are the message generators of the
+// commitment scheme in G_q.
+func (ps *DoubleDiscreteLogProofSystem) hProduct(msgs []*big.Int) *big.Int {
+	var p product
+	ps.CommSchemeInGq.messageTerms(&p, msgs...)
+	return p.eval(ps.gq)
 }
 
+// generateChallenge derives the challenge from a transcript of the context, both commitment
+// schemes, the security parameter, the statement (the commitments c and d), the prover's
+// commitments and the vote.
 func (ps *DoubleDiscreteLogProofSystem) generateChallenge(commToU, commToAandB, t *big.Int, t1Arr,
 	t2Arr []*big.Int, vote string) *big.Int {
-	inputs := [][]*big.Int{{commToU, commToAandB, t}, t1Arr, t2Arr}
-	sha := sha256.New()
-	for _, c := range inputs {
-		for _, elem := range c {
-			sha.Write(elem.Bytes())
-		}
-	}
-	sha.Write([]byte(vote))
-	hash := sha.Sum(nil)
-	ch := new(big.Int).SetBytes(hash)
-	return ch.Mod(ch, ps.zp.Modulus)
+
+	tr := NewTranscript(ddLogProtocol)
+	tr.AppendBytes("context", ps.Context)
+	tr.AppendCommitmentScheme("comm_scheme_p", &ps.CommSchemeInGp)
+	tr.AppendCommitmentScheme("comm_scheme_q", &ps.CommSchemeInGq)
+	tr.AppendInt("security_param", big.NewInt(int64(ps.SecurityParam)))
+	tr.AppendInt("comm_u", commToU)
+	tr.AppendInt("comm_a_b", commToAandB)
+	tr.AppendInt("t", t)
+	tr.AppendInts("t1", t1Arr)
+	tr.AppendInts("t2", t2Arr)
+	tr.AppendString("vote", vote)
+	return tr.Challenge(ps.zp.Modulus)
 }
 
+// ddLogProtocol is the protocol label of the double discrete logarithm proof's transcript.
+const ddLogProtocol = "double-discrete-log"
+
 // ddLogProofDTO is needed for Tendermint serialization and deserialization.
 type ddLogProofDTO struct {
 	T     Int   `json:"t"`
@@ -223,12 +328,15 @@ type ddLogProofDTO struct {
 func (p DdLogProof) MarshalAmino() (string, error) {
 	dto := ddLogProofDTO{}
 	p.wrapInDTO(&dto)
-	return string(amino.MustMarshalBinaryBare(dto)), nil
+	bz, err := amino.MarshalBinaryBare(dto)
+	return string(bz), err
 }
 
 func (p *DdLogProof) UnmarshalAmino(bytes []byte) error {
 	var dto ddLogProofDTO
-	amino.MustUnmarshalBinaryBare(bytes, &dto)
+	if err := amino.UnmarshalBinaryBare(bytes, &dto); err != nil {
+		return err
+	}
 	p.unwrapDTO(dto)
 	return nil
 }
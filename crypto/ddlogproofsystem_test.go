@@ -29,27 +29,62 @@ func TestDDLogProofSystem(t *testing.T) {
 	q, _ := new(big.Int).SetString(qTest, 10)
 
 	gP := NewGStarModPrime(o, p)
-	commP := NewPedersenCommitmentScheme(gP, gP.RandomGenerator(),
+	commP := NewPedersenCommitmentScheme(&gP, gP.RandomGenerator(),
 		[]*big.Int{gP.RandomGenerator()})
 
 	gQ := NewGStarModPrime(p, q)
-	commQ := NewPedersenCommitmentScheme(gQ, gQ.RandomGenerator(),
+	commQ := NewPedersenCommitmentScheme(&gQ, gQ.RandomGenerator(),
 		[]*big.Int{gQ.RandomGenerator(), gQ.RandomGenerator()}) // comm_q can take two messages
 
 	voter1 := GenerateNewVoter(commQ)
 
 	// commitment c
 	commToURand := commP.G.ZModOrder().RandomElement()
-	commToU := commP.Commit(commToURand, voter1.U)
+	commToU, err := commP.Commit(commToURand, voter1.U)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// commitment d
 	commToAandBRand := commQ.G.ZModOrder().RandomElement()
-	commToAandB := commQ.Commit(commToAandBRand, voter1.A, voter1.B)
+	commToAandB, err := commQ.Commit(commToAandBRand, voter1.A, voter1.B)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	ps := NewDoubleDiscreteLogProofSystem(commP, commQ, securityParam)
+	ps, err := NewDoubleDiscreteLogProofSystem(commP, commQ, securityParam)
+	if err != nil {
+		t.Fatal(err)
+	}
 	proof := ps.Generate(voter1, commToU, commToURand, commToAandB,
 		commToAandBRand, "yes")
-	if !ps.Verify(proof, commToU, commToAandB, "yes") {
+	if v, err := ps.Verify(proof, commToU, commToAandB, "yes"); err != nil || !v {
 		t.Fail()
 	}
+	if err := ps.ValidateProof(proof); err != nil {
+		t.Error(err)
+	}
+	proof.ZMArr[0] = proof.ZMArr[0][:1]
+	if ps.ValidateProof(proof) == nil {
+		t.Error("proof with a missing response must be malformed")
+	}
+	proof.ZMArr[0] = []*big.Int{q, big.NewInt(0)}
+	if ps.ValidateProof(proof) == nil {
+		t.Error("proof with a response outside of Z_q must be malformed")
+	}
+	if _, err := ps.Verify(proof, commToU, commToAandB, "yes"); err == nil {
+		t.Error("verification of a malformed proof must fail with an error")
+	}
+}
+
+func TestNewDDLogProofSystemRejectsInvalidParameters(t *testing.T) {
+	commP, commQ := newTestSchemes()
+	p := commP.G.ZModOrder().Modulus
+
+	if _, err := NewDoubleDiscreteLogProofSystem(commQ, commP, securityParam); err == nil {
+		t.Error("swapped groups must be rejected")
+	}
+	if _, err := NewDoubleDiscreteLogProofSystem(commP, commQ, p.BitLen()); err == nil {
+		t.Error("security parameter with 2^k > p must be rejected")
+	}
 }
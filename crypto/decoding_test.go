@@ -0,0 +1,101 @@
+package crypto
+
+import (
+	"fmt"
+	"testing"
+)
+
+// The tests in this file feed malformed encodings to the JSON and amino decoders of the proof
+// transcripts and verify every successfully decoded transcript. Neither decoding nor verification
+// must panic, and no malformed transcript must verify.
+
+// encodedProof is a proof transcript that can be encoded with JSON and amino.
+type encodedProof interface {
+	MarshalJSON() ([]byte, error)
+	MarshalAmino() (string, error)
+}
+
+// malformedEncodings returns the JSON and amino encodings of the given valid proof truncated and
+// with flipped bytes at several positions, and a few degenerate inputs.
+func malformedEncodings(tb testing.TB, proof encodedProof) map[string][]byte {
+	jsonBz, err := proof.MarshalJSON()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	aminoStr, err := proof.MarshalAmino()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	inputs := map[string][]byte{
+		"empty":          {},
+		"empty object":   []byte("{}"),
+		"null":           []byte("null"),
+		"wrong shapes":   []byte(`{"t1_ar":[],"zm_arr":[[]],"c":["0"],"comm":"-1"}`),
+		"negative value": []byte(`{"comm":"-1","resp_a":"-1","resp_b":"-1","resp_s":"-1"}`),
+	}
+	for name, bz := range map[string][]byte{"json": jsonBz, "amino": []byte(aminoStr)} {
+		for _, n := range []int{1, len(bz) / 3, len(bz) / 2, len(bz) - 1} {
+			inputs[fmt.Sprintf("%s truncated to %d bytes", name, n)] = bz[:n]
+		}
+		for _, i := range []int{0, len(bz) / 4, len(bz) / 2, 3 * len(bz) / 4, len(bz) - 1} {
+			flipped := append([]byte{}, bz...)
+			flipped[i] ^= 0x5a
+			inputs[fmt.Sprintf("%s with byte %d flipped", name, i)] = flipped
+		}
+	}
+	return inputs
+}
+
+func TestMalformedPolyEvalProofs(t *testing.T) {
+	e := newTestElection(t, 2, nil)
+	b := e.newBallot(e.voters[0], "yes")
+	for name, bz := range malformedEncodings(t, b.proof1) {
+		var fromJSON, fromAmino PolyEvalProof
+		if fromJSON.UnmarshalJSON(bz) == nil {
+			if v, _ := e.ps1.Verify(fromJSON, b.commToU, b.vote); v {
+				t.Errorf("%s: malformed proof must not verify", name)
+			}
+		}
+		if fromAmino.UnmarshalAmino(bz) == nil {
+			if v, _ := e.ps1.Verify(fromAmino, b.commToU, b.vote); v {
+				t.Errorf("%s: malformed proof must not verify", name)
+			}
+		}
+	}
+}
+
+func TestMalformedDdLogProofs(t *testing.T) {
+	e := newTestElection(t, 2, nil)
+	b := e.newBallot(e.voters[0], "yes")
+	for name, bz := range malformedEncodings(t, b.proof2) {
+		var fromJSON, fromAmino DdLogProof
+		if fromJSON.UnmarshalJSON(bz) == nil {
+			if v, _ := e.ps2.Verify(fromJSON, b.commToU, b.commToAandB, b.vote); v {
+				t.Errorf("%s: malformed proof must not verify", name)
+			}
+		}
+		if fromAmino.UnmarshalAmino(bz) == nil {
+			if v, _ := e.ps2.Verify(fromAmino, b.commToU, b.commToAandB, b.vote); v {
+				t.Errorf("%s: malformed proof must not verify", name)
+			}
+		}
+	}
+}
+
+func TestMalformedPreimageEqualityProofs(t *testing.T) {
+	e := newTestElection(t, 2, nil)
+	b := e.newBallot(e.voters[0], "yes")
+	for name, bz := range malformedEncodings(t, b.proof3) {
+		var fromJSON, fromAmino PreimageEqualityProof
+		if fromJSON.UnmarshalJSON(bz) == nil {
+			if v, _ := e.ps3.Verify(fromJSON, b.commToAandB, b.uHat, b.vote); v {
+				t.Errorf("%s: malformed proof must not verify", name)
+			}
+		}
+		if fromAmino.UnmarshalAmino(bz) == nil {
+			if v, _ := e.ps3.Verify(fromAmino, b.commToAandB, b.uHat, b.vote); v {
+				t.Errorf("%s: malformed proof must not verify", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package crypto
+
+import (
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// This file implements the groups of points of the NIST prime-order elliptic curves. They are much
+// smaller and faster than Schnorr groups of the same security level but are only usable where the
+// UEP protocol does not require the elements of a group to be integers, cf. the double discrete
+// log proof system for which G_q must be a subgroup of Z*_p.
+
+const (
+	CurveP256 = "P-256"
+	CurveP384 = "P-384"
+)
+
+// ECGroup represents the group of points of a prime-order elliptic curve. An element is encoded as
+// the big integer of its compressed SEC 1 encoding. The identity, the point at infinity, is
+// encoded as 0.
+type ECGroup struct {
+	Name  string // Name of the curve, e.g. P-256.
+	curve elliptic.Curve
+}
+
+// NewECGroup creates the group of the elliptic curve with the given name. The supported curves
+// are P-256 and P-384, both of which have prime order.
+func NewECGroup(name string) (*ECGroup, error) {
+	switch name {
+	case CurveP256:
+		return &ECGroup{Name: name, curve: elliptic.P256()}, nil
+	case CurveP384:
+		return &ECGroup{Name: name, curve: elliptic.P384()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported elliptic curve '%s'", name)
+	}
+}
+
+// ZModOrder returns the ring of integers modulo the curve's order.
+func (g *ECGroup) ZModOrder() ZModPrime {
+	return NewZModPrime(g.curve.Params().N)
+}
+
+// Parameters returns the field modulus, the order, the coefficient b and the base point of the
+// curve.
+func (g *ECGroup) Parameters() []*big.Int {
+	params := g.curve.Params()
+	return []*big.Int{params.P, params.N, params.B, params.Gx, params.Gy}
+}
+
+// IdentityElement returns the encoding of the point at infinity, i.e. 0.
+func (g *ECGroup) IdentityElement() *big.Int {
+	return big.NewInt(0)
+}
+
+// Contains checks if the given value is the encoding of a point of this curve.
+func (g *ECGroup) Contains(v *big.Int) bool {
+	if v == nil || v.Sign() < 0 {
+		return false
+	}
+	_, _, ok := g.decode(v)
+	return ok
+}
+
+// IsGenerator checks if the given value is a point of this curve other than the point at
+// infinity. Since the curve has prime order, every such point is a generator.
+func (g *ECGroup) IsGenerator(v *big.Int) bool {
+	return g.Contains(v) && v.Sign() != 0
+}
+
+// Mul adds the points x and y. Both must be elements of the group, i.e. checked with Contains. A
+// value that is not the encoding of a point is treated as the point at infinity.
+func (g *ECGroup) Mul(x, y *big.Int) *big.Int {
+	x1, y1 := g.decodeOrIdentity(x)
+	x2, y2 := g.decodeOrIdentity(y)
+	return g.encode(g.curve.Add(x1, y1, x2, y2))
+}
+
+// Exp multiplies the point base with the scalar exp. The base must be an element of the group,
+// i.e. checked with Contains. Returns the point at infinity if it is not the encoding of a point.
+func (g *ECGroup) Exp(base, exp *big.Int) *big.Int {
+	k := new(big.Int).Mod(exp, g.curve.Params().N)
+	x, y := g.decodeOrIdentity(base)
+	if k.Sign() == 0 || (x.Sign() == 0 && y.Sign() == 0) {
+		return g.IdentityElement()
+	}
+	return g.encode(g.curve.ScalarMult(x, y, k.Bytes()))
+}
+
+// Invert negates the given point. The point must be an element of the group, i.e. checked with
+// Contains. Returns the point at infinity if the value is not the encoding of a point.
+func (g *ECGroup) Invert(v *big.Int) *big.Int {
+	x, y := g.decodeOrIdentity(v)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return g.IdentityElement()
+	}
+	return g.encode(x, new(big.Int).Sub(g.curve.Params().P, y))
+}
+
+// RandomElement gets a random point of this curve other than the point at infinity.
+func (g *ECGroup) RandomElement() *big.Int {
+	return g.encode(g.curve.ScalarBaseMult(RandInt(g.curve.Params().N).Bytes()))
+}
+
+// RandomGenerator gets a random generator of this group. Since the curve has prime order, it is
+// the same as RandomElement.
+func (g *ECGroup) RandomGenerator() *big.Int {
+	return g.RandomElement()
+}
+
+// HashToElement deterministically maps the given data to a point of this curve other than the
+// point at infinity. The data is hashed to an x-coordinate. If there is no point with that
+// x-coordinate, the procedure is repeated with an incremented counter.
+func (g *ECGroup) HashToElement(data ...[]byte) *big.Int {
+	params := g.curve.Params()
+	byteLen := (params.BitSize + 7) / 8
+	for counter := uint32(0); ; counter++ {
+		x := new(big.Int).SetBytes(expandHash(byteLen+16, counter, data...))
+		x.Mod(x, params.P)
+		compressed := make([]byte, 1+byteLen)
+		compressed[0] = 2
+		x.FillBytes(compressed[1:])
+		if px, py := elliptic.UnmarshalCompressed(g.curve, compressed); px != nil {
+			return g.encode(px, py)
+		}
+	}
+}
+
+// GeneratorFromSeed deterministically derives a generator of this group from the given public seed
+// and index. The curve's name is part of the hashed data.
+func (g *ECGroup) GeneratorFromSeed(seed string, index uint32) *big.Int {
+	var idx [4]byte
+	binary.BigEndian.PutUint32(idx[:], index)
+	return g.HashToElement([]byte(generatorDomain), []byte(g.Name), []byte(seed), idx[:])
+}
+
+// ElementBytes returns the compressed SEC 1 encoding of the given point or a single zero byte for
+// the point at infinity.
+func (g *ECGroup) ElementBytes(v *big.Int) []byte {
+	if v.Sign() == 0 {
+		return []byte{0}
+	}
+	return v.Bytes()
+}
+
+// ElementFromBytes decodes a point encoded with ElementBytes.
+func (g *ECGroup) ElementFromBytes(bytes []byte) (*big.Int, error) {
+	v := new(big.Int).SetBytes(bytes)
+	if (v.Sign() == 0 && len(bytes) != 1) || !g.Contains(v) {
+		return nil, errors.New("bytes do not encode a point of the curve")
+	}
+	return v, nil
+}
+
+// Validate checks that the curve is one of the supported curves.
+func (g *ECGroup) Validate() error {
+	if g.curve == nil {
+		return errors.New("the curve of the group is not set")
+	}
+	return nil
+}
+
+// String returns a string representation of this group.
+func (g *ECGroup) String() string {
+	return fmt.Sprintf("ECGroup: {curve=%s}", g.Name)
+}
+
+// encode returns the encoding of the given point. The point (0, 0) is the point at infinity.
+func (g *ECGroup) encode(x, y *big.Int) *big.Int {
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return g.IdentityElement()
+	}
+	return new(big.Int).SetBytes(elliptic.MarshalCompressed(g.curve, x, y))
+}
+
+// decode returns the coordinates of the encoded point and whether the encoding is valid. The point
+// at infinity is returned as (0, 0).
+func (g *ECGroup) decode(v *big.Int) (x, y *big.Int, ok bool) {
+	if v.Sign() == 0 {
+		return new(big.Int), new(big.Int), true
+	}
+	byteLen := (g.curve.Params().BitSize + 7) / 8
+	bytes := v.Bytes()
+	if len(bytes) != 1+byteLen {
+		return new(big.Int), new(big.Int), false
+	}
+	x, y = elliptic.UnmarshalCompressed(g.curve, bytes)
+	if x == nil {
+		return new(big.Int), new(big.Int), false
+	}
+	return x, y, true
+}
+
+// decodeOrIdentity returns the coordinates of the encoded point like decode, or the point at
+// infinity if the value is not the encoding of a point. Like the other groups, the group
+// operations do not panic on values outside the group.
+func (g *ECGroup) decodeOrIdentity(v *big.Int) (x, y *big.Int) {
+	if v != nil && v.Sign() >= 0 {
+		if x, y, ok := g.decode(v); ok {
+			return x, y
+		}
+	}
+	return new(big.Int), new(big.Int)
+}
@@ -0,0 +1,176 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestECGroup(t *testing.T) {
+	g, err := NewECGroup(CurveP256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewECGroup("P-224"); err == nil {
+		t.Error("unsupported curves must be rejected")
+	}
+	zq := g.ZModOrder()
+	x, y := g.RandomElement(), g.RandomElement()
+	if !g.IsGenerator(x) || !g.Contains(g.IdentityElement()) || g.IsGenerator(g.IdentityElement()) {
+		t.Error("random elements must be generators and the identity must not")
+	}
+	if g.Mul(x, g.Invert(x)).Cmp(g.IdentityElement()) != 0 {
+		t.Error("an element multiplied with its inverse must be the identity")
+	}
+	if g.Mul(x, g.IdentityElement()).Cmp(x) != 0 {
+		t.Error("multiplication with the identity must not change the element")
+	}
+	a, b := zq.RandomElement(), zq.RandomElement()
+	if g.Mul(g.Exp(x, a), g.Exp(x, b)).Cmp(g.Exp(x, zq.Add(a, b))) != 0 {
+		t.Error("x^a * x^b must be equal to x^(a+b)")
+	}
+	if g.Exp(y, zq.Modulus).Cmp(g.IdentityElement()) != 0 {
+		t.Error("an element to the power of the group order must be the identity")
+	}
+	invalidPrefix := new(big.Int).SetBytes(append([]byte{4}, make([]byte, 32)...))
+	if g.Contains(new(big.Int).Lsh(x, 8)) || g.Contains(invalidPrefix) || g.Contains(big.NewInt(-1)) {
+		t.Error("invalid encodings must not be elements")
+	}
+	for _, v := range []*big.Int{x, g.IdentityElement()} {
+		decoded, err := g.ElementFromBytes(g.ElementBytes(v))
+		if err != nil || decoded.Cmp(v) != 0 {
+			t.Error("decoding an encoded element must result in the same element")
+		}
+	}
+	h := g.HashToElement([]byte("data"))
+	if !g.IsGenerator(h) || h.Cmp(g.HashToElement([]byte("data"))) != 0 {
+		t.Error("hashing must deterministically result in a generator")
+	}
+}
+
+func TestECGroupTreatsInvalidEncodingAsIdentity(t *testing.T) {
+	g, _ := NewECGroup(CurveP256)
+	x := g.RandomElement()
+	for _, invalid := range []*big.Int{new(big.Int).Lsh(x, 8), big.NewInt(-1), nil} {
+		if g.Contains(invalid) {
+			t.Fatalf("%v must not be an element of the group", invalid)
+		}
+		if g.Mul(x, invalid).Cmp(x) != 0 {
+			t.Errorf("Mul must treat %v as the identity", invalid)
+		}
+		if g.Exp(invalid, big.NewInt(2)).Cmp(g.IdentityElement()) != 0 {
+			t.Errorf("Exp must return the identity for %v", invalid)
+		}
+		if g.Invert(invalid).Cmp(g.IdentityElement()) != 0 {
+			t.Errorf("Invert must return the identity for %v", invalid)
+		}
+	}
+}
+
+func TestPedersenCommitmentSchemeInECGroup(t *testing.T) {
+	g, _ := NewECGroup(CurveP256)
+	comm := NewPedersenCommitmentSchemeFromSeed(g, "seed", 2)
+	if err := comm.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	bz, err := comm.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded PedersenCommitmentScheme
+	if err := decoded.UnmarshalJSON(bz); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := decoded.G.(*ECGroup); !ok || !decoded.IsDerivedFromSeed("seed") {
+		t.Error("decoded scheme must be equal to the encoded scheme")
+	}
+
+	voter := GenerateNewVoter(comm)
+	hHat := g.HashToElement([]byte("election generator"))
+	uHat := g.Exp(hHat, voter.B)
+	commToAandBRand := g.ZModOrder().RandomElement()
+	commToAandB, err := comm.Commit(commToAandBRand, voter.A, voter.B)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := NewPreimageEqualityProofSystem(hHat, comm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof := ps.Generate(voter, commToAandB, commToAandBRand, uHat, "yes")
+	if v, err := ps.Verify(proof, commToAandB, uHat, "yes"); err != nil || !v {
+		t.Error("pre-image proof in an elliptic curve group must verify")
+	}
+	if v, _ := ps.Verify(proof, commToAandB, uHat, "no"); v {
+		t.Error("pre-image proof must not verify for another vote")
+	}
+}
+
+func TestPolyEvalProofSystemInECGroup(t *testing.T) {
+	g, _ := NewECGroup(CurveP256)
+	comm := NewPedersenCommitmentSchemeFromSeed(g, "seed", 1)
+	zn := g.ZModOrder()
+	u := zn.RandomElement()
+	poly := NewPolynomial([]*big.Int{big.NewInt(1)}, zn)
+	poly = poly.IncludeCredential(zn.RandomElement())
+	poly = poly.IncludeCredential(u)
+	poly = poly.IncludeCredential(zn.RandomElement())
+
+	r := zn.RandomElement()
+	commToU, err := comm.Commit(r, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := NewPolynomialEvaluationProofSystem(comm, poly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof := ps.Generate(u, r, commToU, "yes")
+	if v, err := ps.Verify(proof, commToU, "yes"); err != nil || !v {
+		t.Error("membership proof in an elliptic curve group must verify")
+	}
+}
+
+func TestDDLogProofSystemRejectsECGroupAsGq(t *testing.T) {
+	g, _ := NewECGroup(CurveP256)
+	commP, _ := newTestSchemes()
+	commQ := NewPedersenCommitmentSchemeFromSeed(g, "seed", 2)
+	if _, err := NewDoubleDiscreteLogProofSystem(commP, commQ, 80); err == nil {
+		t.Error("G_q must be a subgroup of Z*_p")
+	}
+}
+
+// benchmarkPreimageEqualityProof generates and verifies a pre-image proof in the given group and
+// reports the size of the proof's binary encoding.
+func benchmarkPreimageEqualityProof(b *testing.B, g Group) {
+	comm := NewPedersenCommitmentSchemeFromSeed(g, "seed", 2)
+	voter := GenerateNewVoter(comm)
+	hHat := g.HashToElement([]byte("election generator"))
+	uHat := g.Exp(hHat, voter.B)
+	rand := g.ZModOrder().RandomElement()
+	commToAandB, _ := comm.Commit(rand, voter.A, voter.B)
+	ps, err := NewPreimageEqualityProofSystem(hHat, comm)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var size int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		proof := ps.Generate(voter, commToAandB, rand, uHat, "yes")
+		if v, _ := ps.Verify(proof, commToAandB, uHat, "yes"); !v {
+			b.Fatal("proof must verify")
+		}
+		bz, _ := proof.MarshalAmino()
+		size = len(bz)
+	}
+	b.ReportMetric(float64(size), "proof-bytes")
+}
+
+func BenchmarkPreimageEqualityProofSchnorr1024(b *testing.B) {
+	_, gQ := newSchnorrGroups(oTest, pTest, qTest)
+	benchmarkPreimageEqualityProof(b, gQ)
+}
+
+func BenchmarkPreimageEqualityProofP256(b *testing.B) {
+	g, _ := NewECGroup(CurveP256)
+	benchmarkPreimageEqualityProof(b, g)
+}
@@ -0,0 +1,116 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+)
+
+// This file contains the fixtures shared by the tests and benchmarks of the proof systems.
+
+// newSchnorrGroups returns the Schnorr groups G_p and G_q defined by the given primes o, p and q in
+// decimal notation.
+func newSchnorrGroups(o, p, q string) (gP, gQ *GStarModPrime) {
+	oInt, _ := new(big.Int).SetString(o, 10)
+	pInt, _ := new(big.Int).SetString(p, 10)
+	qInt, _ := new(big.Int).SetString(q, 10)
+	groupP := NewGStarModPrime(oInt, pInt)
+	groupQ := NewGStarModPrime(pInt, qInt)
+	return &groupP, &groupQ
+}
+
+// newTestSchemes returns the commitment schemes CommP and CommQ over the small test groups with
+// generators derived from the seed "seed".
+func newTestSchemes() (commP, commQ PedersenCommitmentScheme) {
+	gP, gQ := newSchnorrGroups(oTest, pTest, qTest)
+	return NewPedersenCommitmentSchemeFromSeed(gP, "seed", 1),
+		NewPedersenCommitmentSchemeFromSeed(gQ, "seed", 2)
+}
+
+// testElection is an election over the small test groups with the given number of voters and the
+// proof systems a ballot is generated and verified with.
+type testElection struct {
+	commP  PedersenCommitmentScheme
+	commQ  PedersenCommitmentScheme
+	hHat   *big.Int
+	voters []Voter
+	poly   Polynomial
+	ps1    PolynomialEvaluationProofSystem
+	ps2    DoubleDiscreteLogProofSystem
+	ps3    PreimageEqualityProofSystem
+}
+
+// newTestElection creates an election with the given number of voters, all of which are roots of
+// the credential polynomial. The proof systems are bound to the given context.
+func newTestElection(tb testing.TB, voters int, context []byte) testElection {
+	e := testElection{voters: make([]Voter, voters)}
+	e.commP, e.commQ = newTestSchemes()
+	e.hHat = e.commQ.G.HashToElement([]byte("election generator"))
+	roots := make([]*big.Int, voters)
+	for i := range e.voters {
+		e.voters[i] = GenerateNewVoter(e.commQ)
+		roots[i] = e.voters[i].U
+	}
+	e.poly = FromRoots(roots, e.commP.G.ZModOrder())
+
+	var err error
+	if e.ps1, err = NewPolynomialEvaluationProofSystem(e.commP, e.poly); err != nil {
+		tb.Fatal(err)
+	}
+	if e.ps2, err = NewDoubleDiscreteLogProofSystem(e.commP, e.commQ, securityParam); err != nil {
+		tb.Fatal(err)
+	}
+	if e.ps3, err = NewPreimageEqualityProofSystem(e.hHat, e.commQ); err != nil {
+		tb.Fatal(err)
+	}
+	e.ps1.Context, e.ps2.Context, e.ps3.Context = context, context, context
+	return e
+}
+
+// testBallot holds the statement, the randomness of the commitments and the proofs of a ballot.
+type testBallot struct {
+	vote            string
+	commToU         *big.Int
+	commToURand     *big.Int
+	commToAandB     *big.Int
+	commToAandBRand *big.Int
+	uHat            *big.Int
+	proof1          PolyEvalProof
+	proof2          DdLogProof
+	proof3          PreimageEqualityProof
+}
+
+// newBallot generates a valid ballot with the given vote for the given voter. It is safe to call
+// from multiple goroutines.
+func (e *testElection) newBallot(voter Voter, vote string) testBallot {
+	b := testBallot{
+		vote:            vote,
+		commToURand:     e.commP.G.ZModOrder().RandomElement(),
+		commToAandBRand: e.commQ.G.ZModOrder().RandomElement(),
+		uHat:            e.commQ.G.Exp(e.hHat, voter.B),
+	}
+	b.commToU = e.commP.commit(b.commToURand, voter.U)
+	b.commToAandB = e.commQ.commit(b.commToAandBRand, voter.A, voter.B)
+	b.proof1 = e.ps1.Generate(voter.U, b.commToURand, b.commToU, vote)
+	b.proof2 = e.ps2.Generate(voter, b.commToU, b.commToURand, b.commToAandB, b.commToAandBRand,
+		vote)
+	b.proof3 = e.ps3.Generate(voter, b.commToAandB, b.commToAandBRand, b.uHat, vote)
+	return b
+}
+
+// verify verifies the three proofs of the given ballot individually. Returns an error if any of
+// them is malformed.
+func (e *testElection) verify(b testBallot) (bool, error) {
+	v1, err := e.ps1.Verify(b.proof1, b.commToU, b.vote)
+	if err != nil {
+		return false, err
+	}
+	v2, err := e.ps2.Verify(b.proof2, b.commToU, b.commToAandB, b.vote)
+	if err != nil {
+		return false, err
+	}
+	v3, err := e.ps3.Verify(b.proof3, b.commToAandB, b.uHat, b.vote)
+	if err != nil {
+		return false, err
+	}
+	return v1 && v2 && v3, nil
+}
@@ -0,0 +1,80 @@
+//go:build go1.18
+// +build go1.18
+
+package crypto
+
+import "testing"
+
+// The fuzz targets in this file feed arbitrary bytes to the JSON and amino decoders of the proof
+// transcripts and verify every successfully decoded transcript. Neither decoding nor verification
+// must panic. The seed corpus consists of the encodings of a valid proof and the malformed
+// encodings of the table tests in decoding_test.go.
+
+func FuzzPolyEvalProofDecoding(f *testing.F) {
+	e := newTestElection(f, 2, nil)
+	b := e.newBallot(e.voters[0], "yes")
+	addProofSeeds(f, b.proof1)
+
+	f.Fuzz(func(t *testing.T, bz []byte) {
+		var decoded PolyEvalProof
+		if decoded.UnmarshalJSON(bz) == nil {
+			_, _ = e.ps1.Verify(decoded, b.commToU, b.vote)
+		}
+		decoded = PolyEvalProof{}
+		if decoded.UnmarshalAmino(bz) == nil {
+			_, _ = e.ps1.Verify(decoded, b.commToU, b.vote)
+		}
+	})
+}
+
+func FuzzDdLogProofDecoding(f *testing.F) {
+	e := newTestElection(f, 2, nil)
+	b := e.newBallot(e.voters[0], "yes")
+	addProofSeeds(f, b.proof2)
+
+	f.Fuzz(func(t *testing.T, bz []byte) {
+		var decoded DdLogProof
+		if decoded.UnmarshalJSON(bz) == nil {
+			_, _ = e.ps2.Verify(decoded, b.commToU, b.commToAandB, b.vote)
+		}
+		decoded = DdLogProof{}
+		if decoded.UnmarshalAmino(bz) == nil {
+			_, _ = e.ps2.Verify(decoded, b.commToU, b.commToAandB, b.vote)
+		}
+	})
+}
+
+func FuzzPreimageEqualityProofDecoding(f *testing.F) {
+	e := newTestElection(f, 2, nil)
+	b := e.newBallot(e.voters[0], "yes")
+	addProofSeeds(f, b.proof3)
+
+	f.Fuzz(func(t *testing.T, bz []byte) {
+		var decoded PreimageEqualityProof
+		if decoded.UnmarshalJSON(bz) == nil {
+			_, _ = e.ps3.Verify(decoded, b.commToAandB, b.uHat, b.vote)
+		}
+		decoded = PreimageEqualityProof{}
+		if decoded.UnmarshalAmino(bz) == nil {
+			_, _ = e.ps3.Verify(decoded, b.commToAandB, b.uHat, b.vote)
+		}
+	})
+}
+
+// addProofSeeds adds the JSON and amino encodings of the given valid proof and its malformed
+// encodings to the seed corpus.
+func addProofSeeds(f *testing.F, proof encodedProof) {
+	jsonBz, err := proof.MarshalJSON()
+	if err != nil {
+		f.Fatal(err)
+	}
+	aminoStr, err := proof.MarshalAmino()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(jsonBz)
+	f.Add([]byte(aminoStr))
+	for _, bz := range malformedEncodings(f, proof) {
+		f.Add(bz)
+	}
+}
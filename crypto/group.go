@@ -0,0 +1,61 @@
+package crypto
+
+import (
+	"math/big"
+)
+
+// Group is a cyclic group of prime order in which the discrete logarithm problem is believed to be
+// hard. The commitment scheme and the proof systems are implemented for any such group.
+//
+// Elements are represented as big integers. For groups whose elements are not integers, e.g. the
+// points of an elliptic curve, the integer is an encoding of the element. Exponents are elements
+// of the ring Z_q, where q is the order of the group.
+//
+// The package ships with the Schnorr groups GStarModPrime and the elliptic curve groups ECGroup.
+type Group interface {
+	// ZModOrder returns the ring of integers modulo the group's order, i.e. the ring of exponents.
+	ZModOrder() ZModPrime
+	// Parameters returns the integers defining the group. They are absorbed into proof transcripts.
+	Parameters() []*big.Int
+
+	// IdentityElement returns the identity element of the group.
+	IdentityElement() *big.Int
+	// Contains checks if the given value is an element of the group.
+	Contains(v *big.Int) bool
+	// IsGenerator checks if the given value is a generator of the group, i.e. an element other
+	// than the identity.
+	IsGenerator(v *big.Int) bool
+
+	// Mul applies the group operation to the elements x and y.
+	Mul(x, y *big.Int) *big.Int
+	// Exp applies the group operation exp times to the element base.
+	Exp(base, exp *big.Int) *big.Int
+	// Invert returns the inverse of the given element.
+	Invert(v *big.Int) *big.Int
+
+	// RandomElement returns a uniformly random element of the group.
+	RandomElement() *big.Int
+	// RandomGenerator returns a uniformly random generator of the group.
+	RandomGenerator() *big.Int
+	// HashToElement deterministically maps the given data to a generator of the group whose
+	// discrete logarithm with respect to any other element is unknown.
+	HashToElement(data ...[]byte) *big.Int
+	// GeneratorFromSeed deterministically derives a generator from the given public seed and
+	// index.
+	GeneratorFromSeed(seed string, index uint32) *big.Int
+
+	// ElementBytes returns the canonical encoding of the given element.
+	ElementBytes(v *big.Int) []byte
+	// ElementFromBytes decodes an element encoded with ElementBytes. Returns an error if the bytes
+	// do not encode an element of the group.
+	ElementFromBytes(bytes []byte) (*big.Int, error)
+
+	// Validate checks that the group's parameters define a group of prime order.
+	Validate() error
+	String() string
+}
+
+var (
+	_ Group = &GStarModPrime{}
+	_ Group = &ECGroup{}
+)
@@ -1,9 +1,13 @@
 package crypto
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/tendermint/go-amino"
+	"io"
 	"math/big"
 )
 
@@ -20,13 +24,17 @@ const (
 
 	// Security parameter used in the double discrete log proof system.
 	SecurityParam = 80
+	// Security parameter used in the double discrete log proof system with the 2048 bit groups.
+	// Discrete logarithms in 2048 bit groups offer about 112 bits of security, which the proof's
+	// soundness error of 2^-112 matches.
+	SecurityParam2048 = 112
 
 	// Group orders and modulus for the two required groups G_p and G_q of the UEP protocol.
 	// 160 bit, order q of G_q
 	Q string = "1081119563825030427708677600856959359670713108783"
 	// P = a * Q + 1, 1024 bit, order p of G_p and modulus of G_q
 	P string = "132981118064499312972124229719551507064282251442693318094413647002876359530119444044769383265695686373097209253015503887096288112369989708235068428214124661556800389180762828009952422599372290980806417384771730325122099441368051976156139223257233269955912341167062173607119895128870594055324929155200165347329"
-	// O = 981 * P + 1, 1034 bit, modulus of G_p
+	// O = 980 * P + 1, 1034 bit, modulus of G_p
 	O string = "130321495703209326712681745125160476922996606413839451732525374062818832339517055163873995600381772645635265067955193809354362350122589914070367059649842168325664381397147571449753374147384845161190289037076295718619657452540690936633016438792088604556794094343720930134977497226293182174218430572096162040382421"
 
 	// 1023 bit, order q of G_q
@@ -108,6 +116,68 @@ func (g *GStarModPrime) DefaultGenerator() *big.Int {
 	return generator
 }
 
+// GeneratorFromSeed deterministically derives a generator of this group from the given public seed
+// and index. Since the group's order is prime, every element but the identity is a generator.
+// Generators derived with different seeds or indices are independent, i.e. nobody knows a discrete
+// logarithm relation between them. The group's modulus and order are part of the hashed data.
+func (g *GStarModPrime) GeneratorFromSeed(seed string, index uint32) *big.Int {
+	var idx [4]byte
+	binary.BigEndian.PutUint32(idx[:], index)
+	return g.HashToElement([]byte(generatorDomain), g.Modulus.Bytes(), g.Order.Bytes(),
+		[]byte(seed), idx[:])
+}
+
+// generatorDomain separates the derivation of generators from other uses of hashing into a group.
+const generatorDomain = "up-voting-system/generator"
+
+// HashToElement deterministically maps the given data to an element of this group other than the
+// identity. Nobody knows the discrete logarithm of the resulting element with respect to any other
+// element of the group. The data is hashed to an integer that is 128 bits longer than the modulus
+// which is then reduced modulo p and raised to the cofactor. If the result is the identity element
+// the procedure is repeated with an incremented counter.
+func (g *GStarModPrime) HashToElement(data ...[]byte) *big.Int {
+	cofactor := g.Cofactor()
+	one := big.NewInt(1)
+	byteLen := (g.Modulus.BitLen() + 128 + 7) / 8
+	for counter := uint32(0); ; counter++ {
+		x := new(big.Int).SetBytes(expandHash(byteLen, counter, data...))
+		x.Mod(x, g.Modulus)
+		elem := x.Exp(x, cofactor, g.Modulus)
+		if elem.Sign() > 0 && elem.Cmp(one) != 0 {
+			return elem
+		}
+	}
+}
+
+// expandHash produces byteLen bytes by hashing the counter, a block index and the length-prefixed
+// data items with SHA-256 for consecutive block indices.
+func expandHash(byteLen int, counter uint32, data ...[]byte) []byte {
+	out := make([]byte, 0, byteLen+sha256.Size)
+	for block := uint32(0); len(out) < byteLen; block++ {
+		sha := sha256.New()
+		writeUint32(sha, counter)
+		writeUint32(sha, block)
+		for _, d := range data {
+			writeLengthPrefixed(sha, d)
+		}
+		out = sha.Sum(out)
+	}
+	return out[:byteLen]
+}
+
+func writeUint32(w io.Writer, v uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	w.Write(b[:])
+}
+
+// writeLengthPrefixed writes the length of the data followed by the data itself, such that
+// consecutive writes cannot be confused with each other.
+func writeLengthPrefixed(w io.Writer, data []byte) {
+	writeUint32(w, uint32(len(data)))
+	w.Write(data)
+}
+
 // IdentityElement returns the identity element of this group which is always 1.
 func (g *GStarModPrime) IdentityElement() *big.Int {
 	return big.NewInt(1)
@@ -122,7 +192,8 @@ func (g *GStarModPrime) Cofactor() *big.Int {
 // Contains checks if the given value is an element of this group. The value v is an element of this
 // group if 1 < v < p and v^q = 1 (mod p).
 func (g *GStarModPrime) Contains(v *big.Int) bool {
-	return v.Sign() > 0 &&
+	return v != nil && g.Modulus != nil && g.Order != nil &&
+		v.Sign() > 0 &&
 		v.Cmp(g.Modulus) < 0 &&
 		g.Exp(v, g.Order).Cmp(big.NewInt(1)) == 0
 }
@@ -150,6 +221,60 @@ func (g *GStarModPrime) Invert(i *big.Int) *big.Int {
 	return new(big.Int).ModInverse(i, g.Modulus)
 }
 
+// Validate checks that the modulus and the order of this group are prime and that the order
+// divides modulus - 1, i.e. that modulus = b * order + 1 for some cofactor b.
+func (g *GStarModPrime) Validate() error {
+	if g.Modulus == nil || g.Order == nil {
+		return errors.New("modulus and order of the group must be set")
+	}
+	if g.Order.Sign() <= 0 || g.Order.Cmp(g.Modulus) >= 0 {
+		return errors.New("order of the group must be positive and smaller than the modulus")
+	}
+	if !g.Modulus.ProbablyPrime(primalityRounds) {
+		return errors.New("modulus of the group is not prime")
+	}
+	if !g.Order.ProbablyPrime(primalityRounds) {
+		return errors.New("order of the group is not prime")
+	}
+	if new(big.Int).Mod(new(big.Int).Sub(g.Modulus, big.NewInt(1)), g.Order).Sign() != 0 {
+		return errors.New("order of the group does not divide modulus - 1")
+	}
+	return nil
+}
+
+// IsGenerator checks if the given value is a generator of this group, i.e. an element of the group
+// other than the identity. Since the group has prime order, every such element is a generator.
+func (g *GStarModPrime) IsGenerator(v *big.Int) bool {
+	return g.Contains(v) && v.Cmp(g.IdentityElement()) != 0
+}
+
+// Parameters returns the modulus and the order of this group.
+func (g *GStarModPrime) Parameters() []*big.Int {
+	return []*big.Int{g.Modulus, g.Order}
+}
+
+// ElementBytes returns the big-endian encoding of the given element padded to the byte length of
+// the modulus.
+func (g *GStarModPrime) ElementBytes(v *big.Int) []byte {
+	bytes := make([]byte, (g.Modulus.BitLen()+7)/8)
+	return v.FillBytes(bytes)
+}
+
+// ElementFromBytes decodes an element encoded with ElementBytes.
+func (g *GStarModPrime) ElementFromBytes(bytes []byte) (*big.Int, error) {
+	v := new(big.Int).SetBytes(bytes)
+	if len(bytes) != (g.Modulus.BitLen()+7)/8 || !g.Contains(v) {
+		return nil, errors.New("bytes do not encode an element of the group")
+	}
+	return v, nil
+}
+
+// Equal checks if the given group has the same modulus and order as this group.
+func (g *GStarModPrime) Equal(other GStarModPrime) bool {
+	return g.Modulus != nil && other.Modulus != nil && g.Modulus.Cmp(other.Modulus) == 0 &&
+		g.Order != nil && other.Order != nil && g.Order.Cmp(other.Order) == 0
+}
+
 // RandomBits fetches uniform random random bits with a maximum of the given bit length. If 'exact'
 // is true, exactly bitlen number of bits are set. The returned byte array is in big-endian order,
 // ready for usage with big.Int.
@@ -212,12 +337,15 @@ type gStarModPrimeDTO struct {
 
 func (g GStarModPrime) MarshalAmino() (string, error) {
 	dto := gStarModPrimeDTO{NewInt(g.Modulus), NewInt(g.Order)}
-	return string(amino.MustMarshalBinaryBare(dto)), nil
+	bz, err := amino.MarshalBinaryBare(dto)
+	return string(bz), err
 }
 
 func (g *GStarModPrime) UnmarshalAmino(bytes []byte) error {
 	var dto gStarModPrimeDTO
-	amino.MustUnmarshalBinaryBare(bytes, &dto)
+	if err := amino.UnmarshalBinaryBare(bytes, &dto); err != nil {
+		return err
+	}
 	g.Modulus = dto.Modulus.BigInt()
 	g.Order = dto.Order.BigInt()
 	return nil
@@ -258,6 +386,12 @@ func (g ZModPrime) Contains(n *big.Int) bool {
 	return n.Sign() >= 0 && n.Cmp(g.Modulus) < 0
 }
 
+// Equal checks if this ring and the given ring have the same modulus. The moduli are compared by
+// value, i.e. rings decoded separately are equal.
+func (g ZModPrime) Equal(other ZModPrime) bool {
+	return g.Modulus != nil && other.Modulus != nil && g.Modulus.Cmp(other.Modulus) == 0
+}
+
 // Order gets the order of this ring, i.e. p - 1 because p is prime.
 func (g ZModPrime) Order() *big.Int {
 	return new(big.Int).Sub(g.Modulus, big.NewInt(1))
@@ -307,12 +441,15 @@ func (g ZModPrime) Exp(base, exp *big.Int) *big.Int {
 }
 
 func (g ZModPrime) MarshalAmino() (string, error) {
-	return string(amino.MustMarshalBinaryBare(NewInt(g.Modulus))), nil
+	bz, err := amino.MarshalBinaryBare(NewInt(g.Modulus))
+	return string(bz), err
 }
 
 func (g *ZModPrime) UnmarshalAmino(bytes []byte) error {
 	var i Int
-	amino.MustUnmarshalBinaryBare(bytes, &i)
+	if err := amino.UnmarshalBinaryBare(bytes, &i); err != nil {
+		return err
+	}
 	g.Modulus = i.BigInt()
 	return nil
 }
@@ -376,12 +513,15 @@ func (g ZStarModPrime) Exp(base, exp *big.Int) *big.Int {
 }
 
 func (g ZStarModPrime) MarshalAmino() (string, error) {
-	return string(amino.MustMarshalBinaryBare(NewInt(g.Modulus))), nil
+	bz, err := amino.MarshalBinaryBare(NewInt(g.Modulus))
+	return string(bz), err
 }
 
 func (g *ZStarModPrime) UnmarshalAmino(bytes []byte) error {
 	var i Int
-	amino.MustUnmarshalBinaryBare(bytes, &i)
+	if err := amino.UnmarshalBinaryBare(bytes, &i); err != nil {
+		return err
+	}
 	g.Modulus = i.BigInt()
 	return nil
 }
@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestHashToElement(t *testing.T) {
+	p, _ := new(big.Int).SetString(pTest, 10)
+	q, _ := new(big.Int).SetString(qTest, 10)
+	gQ := NewGStarModPrime(p, q)
+
+	h1 := gQ.HashToElement([]byte("block hash"), []byte("polynomial hash"))
+	h2 := gQ.HashToElement([]byte("block hash"), []byte("polynomial hash"))
+	if h1.Cmp(h2) != 0 {
+		t.Error("hashing the same data must result in the same element")
+	}
+	if !gQ.Contains(h1) || h1.Cmp(gQ.IdentityElement()) == 0 {
+		t.Error("hashed element must be a group element other than the identity")
+	}
+	// Length prefixes make sure that shifting bytes between data items changes the element.
+	h3 := gQ.HashToElement([]byte("block hashp"), []byte("olynomial hash"))
+	if h1.Cmp(h3) == 0 {
+		t.Error("hashing different data must result in different elements")
+	}
+}
+
+func TestGeneratorFromSeed(t *testing.T) {
+	o, _ := new(big.Int).SetString(oTest, 10)
+	p, _ := new(big.Int).SetString(pTest, 10)
+	gP := NewGStarModPrime(o, p)
+
+	commP := NewPedersenCommitmentSchemeFromSeed(&gP, "seed", 2)
+	if !commP.IsDerivedFromSeed("seed") {
+		t.Error("generators must be re-derivable from the seed")
+	}
+	if commP.IsDerivedFromSeed("other seed") {
+		t.Error("generators must depend on the seed")
+	}
+	if commP.Hr.Cmp(commP.Hm[0]) == 0 || commP.Hm[0].Cmp(commP.Hm[1]) == 0 {
+		t.Error("generators with different indices must differ")
+	}
+	for _, h := range append([]*big.Int{commP.Hr}, commP.Hm...) {
+		if !gP.Contains(h) || h.Cmp(gP.IdentityElement()) == 0 {
+			t.Error("derived generator is not a generator of the group")
+		}
+	}
+}
+
+func TestGStarModPrimeValidate(t *testing.T) {
+	o, _ := new(big.Int).SetString(oTest, 10)
+	p, _ := new(big.Int).SetString(pTest, 10)
+	q, _ := new(big.Int).SetString(qTest, 10)
+
+	gP := NewGStarModPrime(o, p)
+	if err := gP.Validate(); err != nil {
+		t.Error(err)
+	}
+	invalid := NewGStarModPrime(o, q)
+	if invalid.Validate() == nil {
+		t.Error("group with an order not dividing modulus - 1 must be invalid")
+	}
+	invalid = NewGStarModPrime(new(big.Int).Mul(o, big.NewInt(3)), p)
+	if invalid.Validate() == nil {
+		t.Error("group with a composite modulus must be invalid")
+	}
+	if gP.IsGenerator(gP.IdentityElement()) || gP.IsGenerator(big.NewInt(0)) ||
+		gP.IsGenerator(nil) {
+		t.Error("identity, zero and nil must not be generators")
+	}
+
+	commP := NewPedersenCommitmentSchemeFromSeed(&gP, "seed", 1)
+	if err := commP.Validate(); err != nil {
+		t.Error(err)
+	}
+	commP.Hm[0] = gP.IdentityElement()
+	if commP.Validate() == nil {
+		t.Error("scheme with the identity as generator must be invalid")
+	}
+}
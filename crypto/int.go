@@ -82,14 +82,15 @@ func (i *Int) UnmarshalJSON(bytes []byte) error {
 	return nil
 }
 
-// IsZero returns true if this integer is zero.
+// IsZero returns true if this integer is zero or not set.
 func (i Int) IsZero() bool {
-	return i.i.Sign() == 0
+	return i.i == nil || i.i.Sign() == 0
 }
 
-// IsNegative returns true if this integer has a negative sign.
+// IsNegative returns true if this integer has a negative sign. An integer that is not set is not
+// negative.
 func (i Int) IsNegative() bool {
-	return i.i.Sign() == -1
+	return i.i != nil && i.i.Sign() == -1
 }
 
 // String returns a string representation of this Int.
@@ -0,0 +1,336 @@
+package crypto
+
+import (
+	"container/list"
+	"math/big"
+	"math/bits"
+	"strings"
+	"sync"
+)
+
+// This file implements the multi-exponentiation engine that evaluates products of powers
+// b_1^e_1 * ... * b_n^e_n in Schnorr groups. Powers of fixed bases, i.e. the generators of the
+// commitment schemes and the election generator, are computed with precomputed window tables,
+// which replace all squarings by table lookups. The remaining powers are computed together with
+// Straus' interleaved window method or, for many bases, with Pippenger's bucket method, both of
+// which share the squarings between all bases. In groups whose elements are not integers, such
+// as elliptic curve groups, the powers are computed one by one.
+
+const (
+	// strausWindow is the window size in bits of Straus' method.
+	strausWindow = 4
+	// pippengerThreshold is the number of bases from which on Pippenger's method is used.
+	pippengerThreshold = 32
+	// fixedBaseWindow is the window size in bits of the fixed-base tables.
+	fixedBaseWindow = 5
+	// maxFixedBaseTableBytes is the maximal size in bytes of all cached fixed-base tables. A table
+	// of a 2048 bit group takes about 3 MB, so the cache holds the tables of the generators of
+	// both commitment schemes and the election generator of the 2048 bit groups.
+	maxFixedBaseTableBytes = 32 << 20
+)
+
+// product represents the product of powers bases[0]^exps[0] * ... * bases[n]^exps[n]. A fixed
+// base is a base that is used over and over again, e.g. a generator of a commitment scheme.
+type product struct {
+	bases []*big.Int
+	exps  []*big.Int
+	fixed []bool
+}
+
+// mul multiplies the product with base^exp.
+func (p *product) mul(base, exp *big.Int) {
+	p.bases = append(p.bases, base)
+	p.exps = append(p.exps, exp)
+	p.fixed = append(p.fixed, false)
+}
+
+// mulFixed multiplies the product with base^exp, where base is a fixed base.
+func (p *product) mulFixed(base, exp *big.Int) {
+	p.bases = append(p.bases, base)
+	p.exps = append(p.exps, exp)
+	p.fixed = append(p.fixed, true)
+}
+
+// mulElement multiplies the product with the given element.
+func (p *product) mulElement(v *big.Int) {
+	p.mul(v, big.NewInt(1))
+}
+
+// eval computes the product in the given group.
+func (p *product) eval(g Group) *big.Int {
+	gp, ok := g.(*GStarModPrime)
+	if !ok {
+		result := g.IdentityElement()
+		for i, base := range p.bases {
+			result = g.Mul(result, g.Exp(base, p.exps[i]))
+		}
+		return result
+	}
+
+	m := modMul{modulus: gp.Modulus}
+	result := big.NewInt(1)
+	var bases, exps []*big.Int
+	for i, base := range p.bases {
+		exp := p.exps[i]
+		if exp.Sign() < 0 || exp.Cmp(gp.Order) >= 0 {
+			exp = new(big.Int).Mod(exp, gp.Order)
+		}
+		switch {
+		case exp.Sign() == 0:
+		case exp.BitLen() == 1:
+			m.mul(result, result, base)
+		case p.fixed[i]:
+			fixedBase(gp, base).expMul(&m, result, exp)
+		default:
+			bases = append(bases, base)
+			exps = append(exps, exp)
+		}
+	}
+	if len(bases) >= pippengerThreshold {
+		m.mul(result, result, pippenger(&m, bases, exps))
+	} else if len(bases) > 0 {
+		m.mul(result, result, straus(&m, bases, exps))
+	}
+	return result
+}
+
+// modMul multiplies integers modulo a modulus.
+type modMul struct {
+	modulus *big.Int
+	tmp     big.Int
+	quo     big.Int
+}
+
+// mul sets z to x * y mod modulus. z may alias x or y.
+func (m *modMul) mul(z, x, y *big.Int) {
+	m.tmp.Mul(x, y)
+	m.quo.QuoRem(&m.tmp, m.modulus, z)
+}
+
+// window returns the w bits of exp starting at the given bit position.
+func window(exp *big.Int, pos, w int) int {
+	d := 0
+	for j := w - 1; j >= 0; j-- {
+		d = d<<1 | int(exp.Bit(pos+j))
+	}
+	return d
+}
+
+// maxBitLen returns the bit length of the largest of the given integers.
+func maxBitLen(vs []*big.Int) int {
+	n := 0
+	for _, v := range vs {
+		if v.BitLen() > n {
+			n = v.BitLen()
+		}
+	}
+	return n
+}
+
+// straus computes the product of the powers bases[i]^exps[i] with Straus' interleaved window
+// method. The exponents must be non-negative.
+func straus(m *modMul, bases, exps []*big.Int) *big.Int {
+	// powers[i][d] = bases[i]^d
+	powers := make([][]*big.Int, len(bases))
+	for i, base := range bases {
+		powers[i] = make([]*big.Int, 1<<strausWindow)
+		powers[i][1] = base
+		for d := 2; d < len(powers[i]); d++ {
+			powers[i][d] = new(big.Int)
+			m.mul(powers[i][d], powers[i][d-1], base)
+		}
+	}
+	windows := (maxBitLen(exps) + strausWindow - 1) / strausWindow
+	result := big.NewInt(1)
+	for k := windows - 1; k >= 0; k-- {
+		if k < windows-1 {
+			for j := 0; j < strausWindow; j++ {
+				m.mul(result, result, result)
+			}
+		}
+		for i, exp := range exps {
+			if d := window(exp, k*strausWindow, strausWindow); d != 0 {
+				m.mul(result, result, powers[i][d])
+			}
+		}
+	}
+	return result
+}
+
+// pippenger computes the product of the powers bases[i]^exps[i] with Pippenger's bucket method.
+// The exponents must be non-negative.
+func pippenger(m *modMul, bases, exps []*big.Int) *big.Int {
+	// The window size minimizing the number of multiplications is about log2(n) - 2.
+	c := 2
+	for n := len(bases) >> 3; n > 1; n >>= 1 {
+		c++
+	}
+	windows := (maxBitLen(exps) + c - 1) / c
+	buckets := make([]*big.Int, 1<<uint(c))
+	result := big.NewInt(1)
+	for k := windows - 1; k >= 0; k-- {
+		if k < windows-1 {
+			for j := 0; j < c; j++ {
+				m.mul(result, result, result)
+			}
+		}
+		for d := range buckets {
+			buckets[d] = nil
+		}
+		for i, exp := range exps {
+			d := window(exp, k*c, c)
+			if d == 0 {
+				continue
+			}
+			if buckets[d] == nil {
+				buckets[d] = new(big.Int).Set(bases[i])
+			} else {
+				m.mul(buckets[d], buckets[d], bases[i])
+			}
+		}
+		// prod(buckets[d]^d) = prod over d of the running product of the buckets d' >= d.
+		var running, sum *big.Int
+		for d := len(buckets) - 1; d > 0; d-- {
+			if buckets[d] != nil {
+				if running == nil {
+					running = buckets[d]
+				} else {
+					m.mul(running, running, buckets[d])
+				}
+			}
+			if running != nil {
+				if sum == nil {
+					sum = new(big.Int).Set(running)
+				} else {
+					m.mul(sum, sum, running)
+				}
+			}
+		}
+		if sum != nil {
+			m.mul(result, result, sum)
+		}
+	}
+	return result
+}
+
+// fixedBaseTable holds the powers base^(d * 2^(w*k)) of a fixed base for all digits
+// 0 < d < 2^w and all windows k of exponents up to a certain bit length, where w is the window
+// size. A power is computed with one multiplication per window and without any squarings.
+type fixedBaseTable struct {
+	bits int
+	rows [][]*big.Int // rows[k][d-1] = base^(d * 2^(w*k))
+}
+
+// newFixedBaseTable precomputes the table of the given base for exponents with up to the given
+// number of bits.
+func newFixedBaseTable(m *modMul, base *big.Int, bits int) *fixedBaseTable {
+	windows := (bits + fixedBaseWindow - 1) / fixedBaseWindow
+	t := &fixedBaseTable{bits: bits, rows: make([][]*big.Int, windows)}
+	b := new(big.Int).Set(base)
+	for k := range t.rows {
+		row := make([]*big.Int, 1<<fixedBaseWindow-1)
+		row[0] = b
+		for d := 1; d < len(row); d++ {
+			row[d] = new(big.Int)
+			m.mul(row[d], row[d-1], b)
+		}
+		t.rows[k] = row
+		// base^(2^(w*(k+1))) = base^((2^w - 1) * 2^(w*k)) * base^(2^(w*k))
+		b = new(big.Int)
+		m.mul(b, row[len(row)-1], row[0])
+	}
+	return t
+}
+
+// expMul multiplies result with base^exp. The exponent must be non-negative.
+func (t *fixedBaseTable) expMul(m *modMul, result, exp *big.Int) {
+	if exp.BitLen() > t.bits {
+		m.mul(result, result, new(big.Int).Exp(t.rows[0][0], exp, m.modulus))
+		return
+	}
+	for k, row := range t.rows {
+		if d := window(exp, k*fixedBaseWindow, fixedBaseWindow); d != 0 {
+			m.mul(result, result, row[d-1])
+		}
+	}
+}
+
+// size returns the number of bytes taken by the powers in the table.
+func (t *fixedBaseTable) size() int {
+	n := 0
+	for _, row := range t.rows {
+		for _, v := range row {
+			n += len(v.Bits()) * bits.UintSize / 8
+		}
+	}
+	return n
+}
+
+// fixedBaseCache caches the tables of fixed bases up to a total size in bytes. If a new table
+// does not fit, the least recently used tables are evicted, which only happens if the fixed bases,
+// i.e. the election parameters, change frequently. A table larger than the cache is not cached.
+type fixedBaseCache struct {
+	sync.Mutex
+	maxBytes int
+	bytes    int
+	tables   map[string]*list.Element
+	lru      *list.List // Cache entries from the most to the least recently used.
+}
+
+// fixedBaseCacheEntry is an entry of a fixedBaseCache.
+type fixedBaseCacheEntry struct {
+	key   string
+	table *fixedBaseTable
+	size  int
+}
+
+// newFixedBaseCache creates an empty cache of the given size in bytes.
+func newFixedBaseCache(maxBytes int) *fixedBaseCache {
+	return &fixedBaseCache{
+		maxBytes: maxBytes,
+		tables:   make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// get returns the table of the given base of the given group. The table is computed on first use.
+func (c *fixedBaseCache) get(g *GStarModPrime, base *big.Int) *fixedBaseTable {
+	key := fixedBaseKey(g, base)
+	c.Lock()
+	defer c.Unlock()
+	if e, ok := c.tables[key]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*fixedBaseCacheEntry).table
+	}
+	t := newFixedBaseTable(&modMul{modulus: g.Modulus}, base, g.Order.BitLen())
+	size := t.size()
+	if size > c.maxBytes {
+		return t
+	}
+	for c.bytes+size > c.maxBytes {
+		e := c.lru.Back()
+		entry := c.lru.Remove(e).(*fixedBaseCacheEntry)
+		delete(c.tables, entry.key)
+		c.bytes -= entry.size
+	}
+	c.tables[key] = c.lru.PushFront(&fixedBaseCacheEntry{key, t, size})
+	c.bytes += size
+	return t
+}
+
+// fixedBaseKey returns the key of the table of the given base of the given group.
+func fixedBaseKey(g *GStarModPrime, base *big.Int) string {
+	var key strings.Builder
+	writeLengthPrefixed(&key, g.Modulus.Bytes())
+	writeLengthPrefixed(&key, base.Bytes())
+	return key.String()
+}
+
+// fixedBaseTables caches the tables of the fixed bases of all groups.
+var fixedBaseTables = newFixedBaseCache(maxFixedBaseTableBytes)
+
+// fixedBase returns the table of the given base of the given group. The table is computed on
+// first use.
+func fixedBase(g *GStarModPrime, base *big.Int) *fixedBaseTable {
+	return fixedBaseTables.get(g, base)
+}
@@ -0,0 +1,149 @@
+package crypto
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// naiveEval computes the given product by computing each power on its own.
+func naiveEval(g Group, p *product) *big.Int {
+	result := g.IdentityElement()
+	for i, base := range p.bases {
+		result = g.Mul(result, g.Exp(base, p.exps[i]))
+	}
+	return result
+}
+
+func TestProductEval(t *testing.T) {
+	p, _ := new(big.Int).SetString(pTest, 10)
+	q, _ := new(big.Int).SetString(qTest, 10)
+	gQ := NewGStarModPrime(p, q)
+	zq := gQ.ZModOrder()
+	qMinusOne := new(big.Int).Sub(q, big.NewInt(1))
+	fixed := []*big.Int{gQ.RandomGenerator(), gQ.RandomGenerator()}
+
+	for _, n := range []int{0, 1, 2, 3, 10, pippengerThreshold, 100} {
+		var prod product
+		for i := 0; i < n; i++ {
+			exp := zq.RandomElement()
+			switch i % 7 {
+			case 1:
+				exp = big.NewInt(0)
+			case 2:
+				exp = big.NewInt(1)
+			case 3:
+				exp = qMinusOne
+			case 4:
+				exp = new(big.Int).Add(q, big.NewInt(5))
+			}
+			if i%3 == 0 {
+				prod.mulFixed(fixed[i%2], exp)
+			} else {
+				prod.mul(gQ.RandomElement(), exp)
+			}
+		}
+		if prod.eval(&gQ).Cmp(naiveEval(&gQ, &prod)) != 0 {
+			t.Errorf("product of %d powers must be equal to the naively computed product", n)
+		}
+	}
+
+	var prod product
+	prod.mulFixed(fixed[0], new(big.Int).Lsh(q, 10))
+	if prod.eval(&gQ).Cmp(naiveEval(&gQ, &prod)) != 0 {
+		t.Error("exponents larger than the fixed-base table must be supported")
+	}
+
+	g, _ := NewECGroup(CurveP256)
+	prod = product{}
+	prod.mulFixed(g.RandomElement(), g.ZModOrder().RandomElement())
+	prod.mul(g.RandomElement(), g.ZModOrder().RandomElement())
+	if prod.eval(g).Cmp(naiveEval(g, &prod)) != 0 {
+		t.Error("product in an elliptic curve group must be equal to the naively computed product")
+	}
+}
+
+// benchmarkParams are the 1024- and 2048-bit parameter sets of G_q.
+var benchmarkParams = []struct {
+	name    string
+	modulus string
+	order   string
+}{
+	{"1024", P1, Q1},
+	{"2048", P2, Q2},
+}
+
+func BenchmarkCommit(b *testing.B) {
+	for _, params := range benchmarkParams {
+		p, _ := new(big.Int).SetString(params.modulus, 10)
+		q, _ := new(big.Int).SetString(params.order, 10)
+		gQ := NewGStarModPrime(p, q)
+		comm := NewPedersenCommitmentSchemeFromSeed(&gQ, "seed", 2)
+		zq := gQ.ZModOrder()
+		r, m1, m2 := zq.RandomElement(), zq.RandomElement(), zq.RandomElement()
+		comm.commit(r, m1, m2) // precomputes the fixed-base tables
+
+		b.Run(fmt.Sprintf("naive-%s", params.name), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var prod product
+				comm.commitTerms(&prod, r, m1, m2)
+				naiveEval(&gQ, &prod)
+			}
+		})
+		b.Run(fmt.Sprintf("fixed-base-%s", params.name), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				comm.commit(r, m1, m2)
+			}
+		})
+	}
+}
+
+func BenchmarkMultiExp(b *testing.B) {
+	for _, params := range benchmarkParams {
+		p, _ := new(big.Int).SetString(params.modulus, 10)
+		q, _ := new(big.Int).SetString(params.order, 10)
+		gQ := NewGStarModPrime(p, q)
+		for _, n := range []int{4, 64} {
+			var prod product
+			for i := 0; i < n; i++ {
+				prod.mul(gQ.RandomElement(), gQ.ZModOrder().RandomElement())
+			}
+			b.Run(fmt.Sprintf("naive-%s-%d", params.name, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					naiveEval(&gQ, &prod)
+				}
+			})
+			b.Run(fmt.Sprintf("engine-%s-%d", params.name, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					prod.eval(&gQ)
+				}
+			})
+		}
+	}
+}
+
+func TestFixedBaseCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	_, gQ := newSchnorrGroups(oTest, pTest, qTest)
+	a, b, c := gQ.RandomGenerator(), gQ.RandomGenerator(), gQ.RandomGenerator()
+	size := newFixedBaseTable(&modMul{modulus: gQ.Modulus}, a, gQ.Order.BitLen()).size()
+
+	cache := newFixedBaseCache(2*size + size/2)
+	ta := cache.get(gQ, a)
+	cache.get(gQ, b)
+	if cache.get(gQ, a) != ta {
+		t.Error("cached table must be reused")
+	}
+	cache.get(gQ, c)
+	if _, ok := cache.tables[fixedBaseKey(gQ, b)]; ok || cache.lru.Len() != 2 {
+		t.Error("least recently used table must be evicted")
+	}
+	if cache.get(gQ, a) != ta || cache.bytes > cache.maxBytes {
+		t.Error("cache must not exceed its size")
+	}
+
+	small := newFixedBaseCache(size / 2)
+	small.get(gQ, a)
+	if small.lru.Len() != 0 || small.bytes != 0 {
+		t.Error("table larger than the cache must not be cached")
+	}
+}
@@ -1,8 +1,9 @@
 package crypto
 
 import (
-	"crypto/sha256"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/tendermint/go-amino"
 	"math"
 	"math/big"
@@ -13,10 +14,12 @@ import (
 // It is used to prove that a voter's public credential is in the set of eligible voters.
 // It is based on the work "A practical system for globally revoking the unlinkable pseudonyms
 // of unknown users" by Stefan Brands et al.
+// An instance can be reused for any number of proofs and is safe for concurrent use.
 type PolynomialEvaluationProofSystem struct {
 	CommScheme PedersenCommitmentScheme // The scheme used to commit to the public credential u.
 	Polynomial Polynomial               // The credential polynomial containing all eligible voters.
-	gStarModPr GStarModPrime
+	Context    []byte                   // Absorbed into every challenge, e.g. to bind proofs to an election.
+	group      Group
 	zModPr     ZModPrime
 	// d is calculated from the order D of the polynomial.
 	// D = 2^(d+1) - 1 ==> d = ceil(log( D + 1)) - 1 = floor( log(D))
@@ -39,26 +42,41 @@ type PolyEvalProof struct {
 // NewPolynomialEvaluationProofSystem creates a new instance of the proof system.
 // The given commitment scheme is the one used to commit to the voter's public credential u.
 // The polynomial is the credential polynomial containing all eligible voter's public credentials.
+// Returns an error if the scheme does not have exactly one message generator, if the polynomial
+// has no roots or if the polynomial's ring is not Z_p, where p is the order of the scheme's group.
 func NewPolynomialEvaluationProofSystem(commScheme PedersenCommitmentScheme,
-	poly Polynomial) PolynomialEvaluationProofSystem {
+	poly Polynomial) (PolynomialEvaluationProofSystem, error) {
 
+	if len(commScheme.Hm) != 1 {
+		return PolynomialEvaluationProofSystem{}, fmt.Errorf("the commitment scheme must have "+
+			"exactly one message generator but has %d", len(commScheme.Hm))
+	}
+	if commScheme.G == nil || poly.ZModPr.Modulus == nil ||
+		commScheme.G.ZModOrder().Modulus == nil ||
+		poly.ZModPr.Modulus.Cmp(commScheme.G.ZModOrder().Modulus) != 0 {
+		return PolynomialEvaluationProofSystem{}, errors.New("the polynomial's ring must be Z_p " +
+			"for the order p of the commitment scheme's group")
+	}
+	if poly.Degree() < 1 {
+		return PolynomialEvaluationProofSystem{}, errors.New("the credential polynomial must at " +
+			"least have degree 1")
+	}
 	return PolynomialEvaluationProofSystem{
 		CommScheme: commScheme,
 		Polynomial: poly,
-		gStarModPr: commScheme.G,
+		group:      commScheme.G,
 		zModPr:     commScheme.G.ZModOrder(),
 		d:          int(math.Floor(math.Log(float64(poly.Degree())) / math.Log(2))),
-	}
+	}, nil
 }
 
 var (
 	// The credential polynomial evaluates Poly(u) = v = 0 for every public credential u that was
 	// added to it. The polynomial evaluation proof system expects commitments to u and to v as
 	// public input. Because v is always 0 the randomness (also input to the commitment) is chosen
-	// to be 0 as well and the commitment thereby takes on the value 1.
-	// These two values are required in the proof generation and verification.
+	// to be 0 as well and the commitment thereby is the identity element of the group.
+	// The randomness is required in the proof generation and verification.
 	commToVRandomness = big.NewInt(0)
-	commToV           = big.NewInt(1)
 )
 
 // Generate generates a polynomial evaluation proof for the given voter public credential u.
@@ -95,31 +113,31 @@ func (ps *PolynomialEvaluationProofSystem) Generate(u, r, commToU *big.Int,
 	// a) c_1 ... c_d
 	cArr := make([]*big.Int, ps.d+1)
 	for i := 1; i < len(cArr); i++ {
-		cArr[i] = ps.CommScheme.Commit(rArr[i], uArr[i])
+		cArr[i] = ps.CommScheme.commit(rArr[i], uArr[i])
 	}
 	cArr = cArr[1:]
 
 	// b) c_f_0 ... c_f_d
 	cfArr := make([]*big.Int, ps.d+1)
 	for i := 0; i < len(cfArr); i++ {
-		cfArr[i] = ps.CommScheme.Commit(sArr[i], fArr[i])
+		cfArr[i] = ps.CommScheme.commit(sArr[i], fArr[i])
 	}
 
 	// c) c_delta_0 ... c_delta_d
 	dArr := ps.calcDeltas(uArr, fArr)
 	cdArr := make([]*big.Int, ps.d+1)
 	for i := 0; i < len(cdArr); i++ {
-		cdArr[i] = ps.CommScheme.Commit(tArr[i], dArr[i])
+		cdArr[i] = ps.CommScheme.commit(tArr[i], dArr[i])
 	}
 
 	// d) c_fu_0 ... c_fu_d-1
 	cfuArr := make([]*big.Int, ps.d)
 	for i := 0; i < len(cfuArr); i++ {
-		cfuArr[i] = ps.CommScheme.Commit(xiArr[i], ps.zModPr.Mul(fArr[i], uArr[i]))
+		cfuArr[i] = ps.CommScheme.commit(xiArr[i], ps.zModPr.Mul(fArr[i], uArr[i]))
 	}
 
-	publicInput := []*big.Int{commToU, commToV}
-	ch := ps.generateChallenge(vote, publicInput, cArr, cfArr, cdArr, cfuArr)
+	commToV := ps.group.IdentityElement()
+	ch := ps.generateChallenge(vote, commToU, commToV, cArr, cfArr, cdArr, cfuArr)
 
 	// Response 1 & 2
 	fBarArr := make([]*big.Int, ps.d+1)
@@ -149,46 +167,114 @@ func (ps *PolynomialEvaluationProofSystem) Generate(u, r, commToU *big.Int,
 	return PolyEvalProof{cArr, cfArr, cdArr, cfuArr, fBarArr, rBarArr, tBar, xiBarArr}
 }
 
-var xFactorPoly Polynomial
-
+// calcDeltas computes the coefficients of degree 0 to d of the polynomial whose evaluation at the
+// challenge the verifier recomputes from the responses. It only uses local state such that proofs
+// can be generated concurrently.
 func (ps *PolynomialEvaluationProofSystem) calcDeltas(uArr, fArr []*big.Int) []*big.Int {
-	ring := ps.Polynomial.ZModPr
-	// This is X^i[j] of the product
-	xFactorPoly = NewPolynomial([]*big.Int{big.NewInt(0), big.NewInt(1)}, ring)
-	// This polynomial is used and extended in each step of the
-	currPoly := NewPolynomial([]*big.Int{big.NewInt(1)}, ring)
-	var result Polynomial
-	result = ps.calcDeltaPolynomial(ps.d+1, 0, currPoly, uArr, fArr, result)
-	return result.Coeffs
+	deltas := newCoeffs(ps.d + 2)
+	ps.calcDeltaPolynomial(ps.d+1, 0, []*big.Int{big.NewInt(1)}, uArr, fArr, deltas)
+	for _, delta := range deltas {
+		delta.Mod(delta, ps.zModPr.Modulus)
+	}
+	return deltas[:ps.d+1]
 }
 
+// calcDeltaPolynomial adds the terms of the subtree of the given level to the deltas. Each path
+// from the root to a leaf, i.e. to a coefficient a_deg of the credential polynomial, multiplies
+// the current polynomial with either X or (f_i + u_i * X) on each level. The deltas are not
+// reduced.
 func (ps *PolynomialEvaluationProofSystem) calcDeltaPolynomial(lvl int, deg int,
-	currPoly Polynomial, uArr []*big.Int, fArr []*big.Int, result Polynomial) Polynomial {
+	currPoly []*big.Int, uArr []*big.Int, fArr []*big.Int, deltas []*big.Int) {
 
 	if lvl == 0 {
-		if deg == 0 {
-			result = currPoly
+		coeff := ps.Polynomial.Coeffs[deg]
+		if coeff.Sign() == 0 {
+			return
 		}
-		return result.Add(currPoly.MulScalar(ps.Polynomial.Coeffs[deg]))
-	} else {
-		result = ps.calcDeltaPolynomial(lvl-1, deg, currPoly.Mul(xFactorPoly), uArr, fArr, result)
+		var tmp big.Int
+		for i, c := range currPoly {
+			deltas[i].Add(deltas[i], tmp.Mul(c, coeff))
+		}
+		return
+	}
+	// Multiplying with X shifts the coefficients.
+	xPoly := append([]*big.Int{big.NewInt(0)}, currPoly...)
+	ps.calcDeltaPolynomial(lvl-1, deg, xPoly, uArr, fArr, deltas)
+
+	nextDeg := deg + 1<<uint(lvl-1)
+	if nextDeg <= ps.Polynomial.Degree() {
+		// The coefficient of X^i of (f + u * X) * currPoly is f * c_i + u * c_(i-1).
+		f, u := fArr[lvl-1], uArr[lvl-1]
+		xuPoly := make([]*big.Int, len(currPoly)+1)
+		for i := range xuPoly {
+			c := new(big.Int)
+			if i < len(currPoly) {
+				c.Mul(f, currPoly[i])
+			}
+			if i > 0 {
+				c.Add(c, new(big.Int).Mul(u, currPoly[i-1]))
+			}
+			xuPoly[i] = c.Mod(c, ps.zModPr.Modulus)
+		}
+		ps.calcDeltaPolynomial(lvl-1, nextDeg, xuPoly, uArr, fArr, deltas)
+	}
+}
 
-		nextDeg := deg + int(math.Pow(2, float64(lvl-1)))
-		if nextDeg <= ps.Polynomial.Degree() {
-			xuFactorPoly := NewPolynomial([]*big.Int{fArr[lvl-1], uArr[lvl-1]}, ps.Polynomial.ZModPr)
-			result = ps.calcDeltaPolynomial(lvl-1, nextDeg, currPoly.Mul(xuFactorPoly), uArr, fArr,
-				result)
+// ValidateProof checks that the arrays of the given transcript have the lengths required for the
+// credential polynomial of this proof system, that all commitments are elements of G_p and that
+// all responses are elements of Z_p.
+func (ps *PolynomialEvaluationProofSystem) ValidateProof(proof PolyEvalProof) error {
+	commitments := []transcriptArray{
+		{"c", proof.CArr, ps.d},
+		{"c_f", proof.CfArr, ps.d + 1},
+		{"c_delta", proof.CdArr, ps.d + 1},
+		{"c_fu", proof.CfuArr, ps.d},
+	}
+	for _, c := range commitments {
+		if err := c.checkElements(ps.group); err != nil {
+			return err
 		}
-		return result
 	}
+	responses := []transcriptArray{
+		{"f_bar", proof.FBarArr, ps.d + 1},
+		{"r_bar", proof.RBarArr, ps.d + 1},
+		{"xi_bar", proof.XiBarArr, ps.d},
+	}
+	for _, r := range responses {
+		if err := r.checkRingElements(ps.zModPr); err != nil {
+			return err
+		}
+	}
+	return checkRingElement(ps.zModPr, "t_bar", proof.TBar)
 }
 
-// Verify verifies the given proof transcript.
+// Verify verifies the given proof transcript. The transcript and the commitment to u are validated
+// first. If they are malformed, an error is returned and no verification takes place.
 func (ps *PolynomialEvaluationProofSystem) Verify(proof PolyEvalProof, commToU *big.Int,
-	vote string) bool {
+	vote string) (bool, error) {
 
 	defer LogExecutionTime(time.Now(), "polynomial evaluation proof verification")
 
+	eqs, err := ps.Equations(proof, commToU, vote)
+	if err != nil {
+		return false, err
+	}
+	return eqs.Hold(), nil
+}
+
+// Equations validates the given proof transcript and commitment like Verify and returns the
+// equations that hold if the proof is valid. They can be verified together with the equations of
+// other proofs with a BatchVerifier.
+func (ps *PolynomialEvaluationProofSystem) Equations(proof PolyEvalProof, commToU *big.Int,
+	vote string) (Equations, error) {
+
+	if err := checkElement(ps.group, "commitment to u", commToU); err != nil {
+		return nil, err
+	}
+	if err := ps.ValidateProof(proof); err != nil {
+		return nil, err
+	}
+
 	cArr := proof.CArr
 	cfArr := proof.CfArr
 	cdArr := proof.CdArr
@@ -199,41 +285,45 @@ func (ps *PolynomialEvaluationProofSystem) Verify(proof PolyEvalProof, commToU *
 	tBar := proof.TBar
 	xiBarArr := proof.XiBarArr
 
-	publicInput := []*big.Int{commToU, commToV}
-	ch := ps.generateChallenge(vote, publicInput, cArr, cfArr, cdArr, cfuArr)
+	commToV := ps.group.IdentityElement()
+	ch := ps.generateChallenge(vote, commToU, commToV, cArr, cfArr, cdArr, cfuArr)
 
 	cArr = append([]*big.Int{commToU}, cArr...)
+	eqs := make(Equations, 0, 2*ps.d+2)
 
-	// Pre-compute c_j^x
-	cxArr := make([]*big.Int, len(cArr))
-	for i := 0; i < len(cArr); i++ {
-		cxArr[i] = ps.gStarModPr.Exp(cArr[i], ch)
-	}
-
-	v := true
-	for i := 0; i < ps.d+1 && v; i++ {
-		comm := ps.CommScheme.Commit(rBarArr[i], fBarArr[i])
-		v = v && (ps.gStarModPr.Mul(cxArr[i], cfArr[i]).Cmp(comm) == 0)
+	// c_j^x * c_f_j = Com(f_bar_j, r_bar_j)
+	for i := 0; i < ps.d+1; i++ {
+		eq := newEquation(ps.group)
+		eq.left.mul(cArr[i], ch)
+		eq.left.mulElement(cfArr[i])
+		ps.CommScheme.commitTerms(&eq.right, rBarArr[i], fBarArr[i])
+		eqs = append(eqs, eq)
 	}
 
+	// c_{j+1}^x * c_j^(-f_bar_j) * c_fu_j = Com(0, xi_bar_j)
 	zero := big.NewInt(0)
-	for i := 0; i < ps.d && v; i++ {
-		comm := ps.CommScheme.Commit(xiBarArr[i], zero)
-		cExpF := ps.gStarModPr.Exp(cArr[i], ps.zModPr.AdditiveInvert(fBarArr[i]))
-		v = v && ps.gStarModPr.Mul(ps.gStarModPr.Mul(cxArr[i+1], cExpF), cfuArr[i]).Cmp(comm) == 0
+	for i := 0; i < ps.d; i++ {
+		eq := newEquation(ps.group)
+		eq.left.mul(cArr[i+1], ch)
+		eq.left.mul(cArr[i], ps.zModPr.AdditiveInvert(fBarArr[i]))
+		eq.left.mulElement(cfuArr[i])
+		ps.CommScheme.commitTerms(&eq.right, xiBarArr[i], zero)
+		eqs = append(eqs, eq)
 	}
 
-	left := ps.gStarModPr.Exp(commToV, ps.zModPr.Exp(ch, big.NewInt(int64(ps.d+1))))
+	// c_v^(x^(d+1)) * prod(c_delta_j^(x^j)) = Com(delta_bar, t_bar)
+	eq := newEquation(ps.group)
+	eq.left.mul(commToV, ps.zModPr.Exp(ch, big.NewInt(int64(ps.d+1))))
 	xi := ps.zModPr.Exp(ch, zero)
 	for i := 0; i <= ps.d; i++ {
-		left = ps.gStarModPr.Mul(left, ps.gStarModPr.Exp(cdArr[i], xi))
+		eq.left.mul(cdArr[i], xi)
 		xi = ps.zModPr.Mul(xi, ch)
 	}
-
 	dBar := ps.calcDeltaBar(fBarArr, ch)
-	v = v && left.Cmp(ps.CommScheme.Commit(tBar, dBar)) == 0
+	ps.CommScheme.commitTerms(&eq.right, tBar, dBar)
+	eqs = append(eqs, eq)
 
-	return v
+	return eqs, nil
 }
 
 func (ps *PolynomialEvaluationProofSystem) calcDeltaBar(fBarArr []*big.Int, ch *big.Int) *big.Int {
@@ -260,21 +350,29 @@ func (ps *PolynomialEvaluationProofSystem) calcDeltaBarTreelike(lvl int, deg int
 	}
 }
 
-func (ps *PolynomialEvaluationProofSystem) generateChallenge(vote string,
-	inputs ...[]*big.Int) *big.Int {
-
-	sha := sha256.New()
-	for _, c := range inputs {
-		for _, elem := range c {
-			sha.Write(elem.Bytes())
-		}
-	}
-	sha.Write([]byte(vote))
-	hash := sha.Sum(nil)
-	ch := new(big.Int).SetBytes(hash)
-	return ch.Mod(ch, ps.zModPr.Modulus)
+// generateChallenge derives the challenge from a transcript of the context, the commitment
+// scheme, the credential polynomial, the statement (the commitments to u and v), the prover's
+// commitments and the vote.
+func (ps *PolynomialEvaluationProofSystem) generateChallenge(vote string, commToU, commToV *big.Int,
+	cArr, cfArr, cdArr, cfuArr []*big.Int) *big.Int {
+
+	t := NewTranscript(polyEvalProtocol)
+	t.AppendBytes("context", ps.Context)
+	t.AppendCommitmentScheme("comm_scheme", &ps.CommScheme)
+	t.AppendBytes("polynomial", ps.Polynomial.Hash())
+	t.AppendInt("comm_u", commToU)
+	t.AppendInt("comm_v", commToV)
+	t.AppendInts("c", cArr)
+	t.AppendInts("c_f", cfArr)
+	t.AppendInts("c_delta", cdArr)
+	t.AppendInts("c_fu", cfuArr)
+	t.AppendString("vote", vote)
+	return t.Challenge(ps.zModPr.Modulus)
 }
 
+// polyEvalProtocol is the protocol label of the polynomial evaluation proof's transcript.
+const polyEvalProtocol = "polynomial-evaluation"
+
 // polyEvalProofDTO is required for Tendermint serialization and deserialization.
 type polyEvalProofDTO struct {
 	CArr   []Int `json:"c"`   // Length d. Doesn't include the commitment to credential u.
@@ -291,12 +389,15 @@ type polyEvalProofDTO struct {
 func (p PolyEvalProof) MarshalAmino() (string, error) {
 	dto := polyEvalProofDTO{}
 	p.wrapInDTO(&dto)
-	return string(amino.MustMarshalBinaryBare(dto)), nil
+	bz, err := amino.MarshalBinaryBare(dto)
+	return string(bz), err
 }
 
 func (p *PolyEvalProof) UnmarshalAmino(bytes []byte) error {
 	var dto polyEvalProofDTO
-	amino.MustUnmarshalBinaryBare(bytes, &dto)
+	if err := amino.UnmarshalBinaryBare(bytes, &dto); err != nil {
+		return err
+	}
 	p.unwrapDTO(dto)
 	return nil
 }
@@ -343,7 +444,7 @@ func (p PolyEvalProof) wrapInDTO(dto *polyEvalProofDTO) {
 		dto.RBarArr[i] = NewInt(v)
 	}
 	dto.TBar = NewInt(p.TBar)
-	dto.XiBarArr = make([]Int, len(p.RBarArr))
+	dto.XiBarArr = make([]Int, len(p.XiBarArr))
 	for i, v := range p.XiBarArr {
 		dto.XiBarArr[i] = NewInt(v)
 	}
@@ -375,7 +476,7 @@ func (p *PolyEvalProof) unwrapDTO(dto polyEvalProofDTO) {
 		p.RBarArr[i] = v.BigInt()
 	}
 	p.TBar = dto.TBar.BigInt()
-	p.XiBarArr = make([]*big.Int, len(dto.RBarArr))
+	p.XiBarArr = make([]*big.Int, len(dto.XiBarArr))
 	for i, v := range dto.XiBarArr {
 		p.XiBarArr[i] = v.BigInt()
 	}
@@ -11,11 +11,11 @@ func TestPolyEvalProofSystem(t *testing.T) {
 	q, _ := new(big.Int).SetString(qTest, 10)
 
 	gP := NewGStarModPrime(o, p)
-	commP := NewPedersenCommitmentScheme(gP, gP.RandomGenerator(),
+	commP := NewPedersenCommitmentScheme(&gP, gP.RandomGenerator(),
 		[]*big.Int{gP.RandomGenerator()})
 
 	gQ := NewGStarModPrime(p, q)
-	commQ := NewPedersenCommitmentScheme(gQ, gQ.RandomGenerator(),
+	commQ := NewPedersenCommitmentScheme(&gQ, gQ.RandomGenerator(),
 		[]*big.Int{gQ.RandomGenerator(), gQ.RandomGenerator()}) // comm_q can take two messages
 
 	voter1 := GenerateNewVoter(commQ)
@@ -30,12 +30,52 @@ func TestPolyEvalProofSystem(t *testing.T) {
 
 	// commitment c
 	commToPubRand := commP.G.ZModOrder().RandomElement()
-	commToPub := commP.Commit(commToPubRand, voter1.U)
+	commToPub, err := commP.Commit(commToPubRand, voter1.U)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// pi_1
-	ps := NewPolynomialEvaluationProofSystem(commP, poly)
+	ps, err := NewPolynomialEvaluationProofSystem(commP, poly)
+	if err != nil {
+		t.Fatal(err)
+	}
 	proof := ps.Generate(voter1.U, commToPubRand, commToPub, "yes")
-	if !ps.Verify(proof, commToPub, "yes") {
+	if v, err := ps.Verify(proof, commToPub, "yes"); err != nil || !v {
 		t.Fail()
 	}
+	if err := ps.ValidateProof(proof); err != nil {
+		t.Error(err)
+	}
+	bz, err := proof.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded PolyEvalProof
+	if err := decoded.UnmarshalJSON(bz); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.ValidateProof(decoded); err != nil {
+		t.Errorf("decoded proof must be well-formed: %v", err)
+	}
+	proof.CfuArr = proof.CfuArr[1:]
+	if ps.ValidateProof(proof) == nil {
+		t.Error("proof with a missing commitment must be malformed")
+	}
+	proof.CfuArr = append(proof.CfuArr, p)
+	if ps.ValidateProof(proof) == nil {
+		t.Error("proof with a commitment outside of G_p must be malformed")
+	}
+	if _, err := ps.Verify(proof, commToPub, "yes"); err == nil {
+		t.Error("verification of a malformed proof must fail with an error")
+	}
+}
+
+func TestNewPolyEvalProofSystemRejectsConstantPolynomial(t *testing.T) {
+	commP, _ := newTestSchemes()
+
+	poly := NewPolynomial([]*big.Int{big.NewInt(1)}, commP.G.ZModOrder())
+	if _, err := NewPolynomialEvaluationProofSystem(commP, poly); err == nil {
+		t.Error("polynomial without roots must be rejected")
+	}
 }
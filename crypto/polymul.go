@@ -0,0 +1,293 @@
+package crypto
+
+import (
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// This file implements the multiplication of polynomials in Z_p[x]. Short polynomials are
+// multiplied with the schoolbook method and longer ones with Karatsuba's method, both of which
+// only reduce the coefficients of the result modulo p. If p - 1 is divisible by a large enough
+// power of two, long polynomials are multiplied with the number-theoretic transform (NTT), i.e.
+// the fast Fourier transform over Z_p, in O(n log n) multiplications. Polynomials with many roots,
+// such as the credential polynomial, are built with a product tree of linear factors such that
+// most multiplications are between polynomials of about the same length.
+
+const (
+	// karatsubaThreshold is the number of coefficients of the shorter factor from which on
+	// Karatsuba's method is used.
+	karatsubaThreshold = 32
+	// nttThreshold is the number of coefficients of the shorter factor from which on the NTT is
+	// used if the modulus allows it.
+	nttThreshold = 512
+	// maxNTTRoots is the maximal number of cached roots of unity.
+	maxNTTRoots = 16
+)
+
+// mulCoeffs multiplies the polynomials with the given coefficients in the given ring and returns
+// the coefficients of the product. The coefficients of the factors must be elements of the ring.
+// The coefficients of the product are new Int instances.
+func mulCoeffs(a, b []*big.Int, zModPr ZModPrime) []*big.Int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	if n >= nttThreshold {
+		if w, size, ok := nttRoot(zModPr.Modulus, len(a)+len(b)-1); ok {
+			return nttMul(a, b, w, size, zModPr.Modulus)
+		}
+	}
+	result := karatsuba(a, b)
+	for _, c := range result {
+		c.Mod(c, zModPr.Modulus)
+	}
+	return result
+}
+
+// karatsuba multiplies the polynomials with the given coefficients with Karatsuba's method. The
+// coefficients of the product are not reduced. The method splits each factor into a lower and an
+// upper half and computes the product with the three products of the lower halves, the upper
+// halves and the sums of both halves instead of the four products of the halves.
+func karatsuba(a, b []*big.Int) []*big.Int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	if len(b) < karatsubaThreshold {
+		return schoolbook(a, b)
+	}
+	result := newCoeffs(len(a) + len(b) - 1)
+	m := len(a) / 2
+	if len(b) <= m {
+		// The factors are unbalanced, so only the longer factor is split.
+		addCoeffsAt(result, karatsuba(a[:m], b), 0)
+		addCoeffsAt(result, karatsuba(a[m:], b), m)
+		return result
+	}
+	z0 := karatsuba(a[:m], b[:m])
+	z2 := karatsuba(a[m:], b[m:])
+	z1 := karatsuba(sumHalves(a, m), sumHalves(b, m))
+	// z1 = (a0 + a1) * (b0 + b1) - a0 * b0 - a1 * b1 = a0 * b1 + a1 * b0
+	for i, c := range z0 {
+		z1[i].Sub(z1[i], c)
+	}
+	for i, c := range z2 {
+		z1[i].Sub(z1[i], c)
+	}
+	addCoeffsAt(result, z0, 0)
+	addCoeffsAt(result, z1, m)
+	addCoeffsAt(result, z2, 2*m)
+	return result
+}
+
+// schoolbook multiplies the polynomials with the given coefficients term by term. The
+// coefficients of the product are not reduced.
+func schoolbook(a, b []*big.Int) []*big.Int {
+	result := newCoeffs(len(a) + len(b) - 1)
+	var tmp big.Int
+	for i, x := range a {
+		if x.Sign() == 0 {
+			continue
+		}
+		for j, y := range b {
+			result[i+j].Add(result[i+j], tmp.Mul(x, y))
+		}
+	}
+	return result
+}
+
+// sumHalves returns the coefficients of the sum of the lower half, i.e. the first m coefficients,
+// and the upper half of the given polynomial.
+func sumHalves(a []*big.Int, m int) []*big.Int {
+	n := m
+	if len(a)-m > n {
+		n = len(a) - m
+	}
+	sum := newCoeffs(n)
+	addCoeffsAt(sum, a[:m], 0)
+	addCoeffsAt(sum, a[m:], 0)
+	return sum
+}
+
+// addCoeffsAt adds the given coefficients to the coefficients of the result starting at the given
+// offset, i.e. it adds the given polynomial multiplied by x^offset.
+func addCoeffsAt(result, coeffs []*big.Int, offset int) {
+	for i, c := range coeffs {
+		result[i+offset].Add(result[i+offset], c)
+	}
+}
+
+// newCoeffs returns n new coefficients which are all 0.
+func newCoeffs(n int) []*big.Int {
+	values := make([]big.Int, n)
+	coeffs := make([]*big.Int, n)
+	for i := range coeffs {
+		coeffs[i] = &values[i]
+	}
+	return coeffs
+}
+
+// nttMul multiplies the polynomials with the given coefficients with the NTT of the given size
+// and the given primitive root of unity of that order.
+func nttMul(a, b []*big.Int, w *big.Int, size int, modulus *big.Int) []*big.Int {
+	m := modMul{modulus: modulus}
+	fa, fb := newCoeffs(size), newCoeffs(size)
+	for i, c := range a {
+		fa[i].Set(c)
+	}
+	for i, c := range b {
+		fb[i].Set(c)
+	}
+	ntt(&m, fa, w)
+	ntt(&m, fb, w)
+	for i := range fa {
+		m.mul(fa[i], fa[i], fb[i])
+	}
+	// The inverse transform is the transform with the inverse root scaled by 1 / size.
+	ntt(&m, fa, new(big.Int).ModInverse(w, modulus))
+	sizeInv := new(big.Int).ModInverse(big.NewInt(int64(size)), modulus)
+	result := fa[:len(a)+len(b)-1]
+	for _, c := range result {
+		m.mul(c, c, sizeInv)
+	}
+	return result
+}
+
+// ntt transforms the given coefficients in place into the evaluations at the powers of the given
+// root of unity, whose order must be the number of coefficients, a power of two. It is the
+// iterative Cooley-Tukey transform.
+func ntt(m *modMul, a []*big.Int, w *big.Int) {
+	n := len(a)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	// twiddles[k] = w^k for 0 <= k < n / 2
+	twiddles := newCoeffs(n / 2)
+	if n > 1 {
+		twiddles[0].SetInt64(1)
+	}
+	for k := 1; k < len(twiddles); k++ {
+		m.mul(twiddles[k], twiddles[k-1], w)
+	}
+	var v big.Int
+	for length := 2; length <= n; length <<= 1 {
+		half, step := length/2, n/length
+		for i := 0; i < n; i += length {
+			for j := 0; j < half; j++ {
+				x, y := a[i+j], a[i+j+half]
+				m.mul(&v, y, twiddles[j*step])
+				y.Sub(x, &v)
+				if y.Sign() < 0 {
+					y.Add(y, m.modulus)
+				}
+				x.Add(x, &v)
+				if x.Cmp(m.modulus) >= 0 {
+					x.Sub(x, m.modulus)
+				}
+			}
+		}
+	}
+}
+
+// nttRoot returns a primitive root of unity modulo the given prime modulus whose order is the
+// smallest power of two that is at least n. Returns false if the modulus has no such root, i.e.
+// if p - 1 is not divisible by that power of two.
+func nttRoot(modulus *big.Int, n int) (*big.Int, int, bool) {
+	size, logSize := 1, 0
+	for size < n {
+		size <<= 1
+		logSize++
+	}
+	root := maxNTTRoot(modulus)
+	if root.log < logSize {
+		return nil, 0, false
+	}
+	// The root of order 2^log raised to 2^(log - logSize) has order 2^logSize.
+	exp := new(big.Int).Lsh(big.NewInt(1), uint(root.log-logSize))
+	return new(big.Int).Exp(root.w, exp, modulus), size, true
+}
+
+// nttRootOfUnity is a primitive root of unity of order 2^log modulo a prime p, where 2^log is the
+// largest power of two dividing p - 1.
+type nttRootOfUnity struct {
+	w   *big.Int
+	log int
+}
+
+// nttRoots caches the roots of unity of the moduli. The cache is cleared when it is full.
+var nttRoots = struct {
+	sync.Mutex
+	roots map[string]nttRootOfUnity
+}{roots: make(map[string]nttRootOfUnity)}
+
+// maxNTTRoot returns the root of unity of the largest order that is a power of two modulo the
+// given prime modulus. The root is computed on first use.
+func maxNTTRoot(modulus *big.Int) nttRootOfUnity {
+	var key strings.Builder
+	writeLengthPrefixed(&key, modulus.Bytes())
+	nttRoots.Lock()
+	defer nttRoots.Unlock()
+	if root, ok := nttRoots.roots[key.String()]; ok {
+		return root
+	}
+	if len(nttRoots.roots) >= maxNTTRoots {
+		nttRoots.roots = make(map[string]nttRootOfUnity)
+	}
+	root := findNTTRoot(modulus)
+	nttRoots.roots[key.String()] = root
+	return root
+}
+
+// findNTTRoot finds the root of unity of the largest order that is a power of two modulo the
+// given prime modulus. For p - 1 = 2^log * c with odd c, x^c is such a root for every quadratic
+// non-residue x. Half of the elements are non-residues, so only few candidates are tried.
+func findNTTRoot(modulus *big.Int) nttRootOfUnity {
+	pMinusOne := new(big.Int).Sub(modulus, big.NewInt(1))
+	log := int(pMinusOne.TrailingZeroBits())
+	if log == 0 {
+		return nttRootOfUnity{log: 0}
+	}
+	c := new(big.Int).Rsh(pMinusOne, uint(log))
+	for x := int64(2); big.NewInt(x).Cmp(modulus) < 0; x++ {
+		if big.Jacobi(big.NewInt(x), modulus) == -1 {
+			return nttRootOfUnity{w: new(big.Int).Exp(big.NewInt(x), c, modulus), log: log}
+		}
+	}
+	// Every odd prime has a non-residue, so the modulus is not prime. The NTT is not used.
+	return nttRootOfUnity{log: 0}
+}
+
+// productOfLinearFactors returns the coefficients of the monic polynomial with the given roots,
+// i.e. of (x - roots[0]) * ... * (x - roots[n]). The linear factors are multiplied pairwise in a
+// product tree. The subtrees are built by up to the given number of goroutines.
+func productOfLinearFactors(roots []*big.Int, zModPr ZModPrime, goroutines int) []*big.Int {
+	switch len(roots) {
+	case 0:
+		return []*big.Int{big.NewInt(1)}
+	case 1:
+		return []*big.Int{zModPr.AdditiveInvert(roots[0]), big.NewInt(1)}
+	}
+	m := len(roots) / 2
+	var left, right []*big.Int
+	if goroutines > 1 {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			left = productOfLinearFactors(roots[:m], zModPr, goroutines/2)
+		}()
+		right = productOfLinearFactors(roots[m:], zModPr, goroutines-goroutines/2)
+		wg.Wait()
+	} else {
+		left = productOfLinearFactors(roots[:m], zModPr, 1)
+		right = productOfLinearFactors(roots[m:], zModPr, 1)
+	}
+	return mulCoeffs(left, right, zModPr)
+}
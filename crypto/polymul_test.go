@@ -0,0 +1,179 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// nttPrime returns a random prime p with the given bit length such that 2^log is the largest power
+// of two dividing p - 1.
+func nttPrime(bits, log int) *big.Int {
+	p := new(big.Int)
+	for {
+		c, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(bits-log)))
+		c.SetBit(c, bits-log-1, 1)
+		c.SetBit(c, 0, 1)
+		p.Lsh(c, uint(log))
+		p.Add(p, big.NewInt(1))
+		if p.ProbablyPrime(primalityRounds) {
+			return p
+		}
+	}
+}
+
+// randomCoeffs returns n random elements of the given ring.
+func randomCoeffs(n int, zModPr ZModPrime) []*big.Int {
+	coeffs := make([]*big.Int, n)
+	for i := range coeffs {
+		coeffs[i] = zModPr.RandomElement()
+	}
+	return coeffs
+}
+
+func TestMulCoeffs(t *testing.T) {
+	q, _ := new(big.Int).SetString(qTest, 10)
+	rings := map[string]ZModPrime{
+		"Z_q": NewZModPrime(q),
+		"NTT": NewZModPrime(nttPrime(256, 16)),
+	}
+	if _, _, ok := nttRoot(q, 2*nttThreshold); ok {
+		t.Fatal("the test modulus q must not allow the NTT")
+	}
+	if _, _, ok := nttRoot(rings["NTT"].Modulus, 1<<16); !ok {
+		t.Fatal("the NTT modulus must allow the NTT")
+	}
+	lengths := [][2]int{{1, 1}, {1, 7}, {5, 5}, {31, 32}, {32, 32}, {33, 70}, {200, 40},
+		{nttThreshold, nttThreshold}, {300, 257}, {1000, 130}}
+	for name, ring := range rings {
+		for _, l := range lengths {
+			a, b := randomCoeffs(l[0], ring), randomCoeffs(l[1], ring)
+			expected := schoolbook(a, b)
+			for _, c := range expected {
+				c.Mod(c, ring.Modulus)
+			}
+			if !NewPolynomial(mulCoeffs(a, b, ring), ring).Equal(NewPolynomial(expected, ring)) {
+				t.Errorf("%s: product of polynomials with %d and %d coefficients must be equal to "+
+					"the schoolbook product", name, l[0], l[1])
+			}
+		}
+	}
+}
+
+func TestNTTRoot(t *testing.T) {
+	for _, log := range []int{1, 8, 20} {
+		p := nttPrime(128, log)
+		w, size, ok := nttRoot(p, 1<<uint(log))
+		if !ok || size != 1<<uint(log) {
+			t.Fatalf("modulus with 2^%d | p - 1 must have a root of order 2^%d", log, log)
+		}
+		order := big.NewInt(int64(size))
+		if new(big.Int).Exp(w, order, p).Cmp(big.NewInt(1)) != 0 ||
+			new(big.Int).Exp(w, order.Rsh(order, 1), p).Cmp(big.NewInt(1)) == 0 {
+			t.Errorf("root must have order exactly 2^%d", log)
+		}
+		if _, _, ok := nttRoot(p, 1<<uint(log)+1); ok {
+			t.Errorf("modulus must not have a root of order 2^%d", log+1)
+		}
+	}
+}
+
+func TestFromRootsProductTree(t *testing.T) {
+	q, _ := new(big.Int).SetString(qTest, 10)
+	for _, zq := range []ZModPrime{NewZModPrime(q), NewZModPrime(nttPrime(256, 16))} {
+		roots := randomCoeffs(500, zq)
+		poly := FromRoots(roots, zq)
+		expected := NewPolynomial([]*big.Int{big.NewInt(1)}, zq)
+		for _, u := range roots {
+			expected = expected.IncludeCredential(u)
+		}
+		if !poly.Equal(expected) {
+			t.Error("product tree must be equal to including the roots one by one")
+		}
+		for _, u := range roots[:10] {
+			if poly.Evaluate(u).Sign() != 0 {
+				t.Error("every root must evaluate to 0")
+			}
+		}
+	}
+}
+
+// BenchmarkFromRoots benchmarks building the credential polynomial of an electorate over the ring
+// of the 1024-bit parameters, which only allows Karatsuba's method, and over a ring of the same
+// size that allows the NTT. Only the smallest electorate is benchmarked in short mode. Karatsuba's
+// method is not benchmarked for a million voters because it takes more than an hour on a
+// single core.
+func BenchmarkFromRoots(b *testing.B) {
+	p1, _ := new(big.Int).SetString(P1, 10)
+	rings := []struct {
+		name      string
+		ring      ZModPrime
+		maxVoters int
+	}{
+		{"karatsuba", NewZModPrime(p1), 100000},
+		{"ntt", NewZModPrime(nttPrime(p1.BitLen(), 24)), 1000000},
+	}
+	for _, voters := range []int{10000, 100000, 1000000} {
+		if testing.Short() && voters > 10000 {
+			continue
+		}
+		for _, r := range rings {
+			if voters > r.maxVoters {
+				continue
+			}
+			roots := randomCoeffs(voters, r.ring)
+			b.Run(fmt.Sprintf("%s-%d", r.name, voters), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					FromRoots(roots, r.ring)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkIncludeCredentials benchmarks building the credential polynomial by including the
+// credentials one by one, which takes quadratic time.
+func BenchmarkIncludeCredentials(b *testing.B) {
+	p1, _ := new(big.Int).SetString(P1, 10)
+	zp := NewZModPrime(p1)
+	for _, voters := range []int{1000, 10000} {
+		if testing.Short() && voters > 1000 {
+			continue
+		}
+		roots := randomCoeffs(voters, zp)
+		b.Run(fmt.Sprintf("%d", voters), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				poly := NewPolynomial([]*big.Int{big.NewInt(1)}, zp)
+				for _, u := range roots {
+					poly = poly.IncludeCredential(u)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkPolyEvalProof benchmarks generating the membership proof for credential polynomials of
+// an electorate over the 1024-bit parameters. Only the smallest electorate is benchmarked in
+// short mode.
+func BenchmarkPolyEvalProof(b *testing.B) {
+	gP, _ := newSchnorrGroups(O1, P1, Q1)
+	comm := NewPedersenCommitmentSchemeFromSeed(gP, "seed", 1)
+	for _, voters := range []int{1000, 10000} {
+		if testing.Short() && voters > 1000 {
+			continue
+		}
+		roots := randomCoeffs(voters, gP.ZModOrder())
+		ps, err := NewPolynomialEvaluationProofSystem(comm, FromRoots(roots, gP.ZModOrder()))
+		if err != nil {
+			b.Fatal(err)
+		}
+		r := gP.ZModOrder().RandomElement()
+		commToU := comm.commit(r, roots[0])
+		b.Run(fmt.Sprintf("generate-%d", voters), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ps.Generate(roots[0], r, commToU, "vote")
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package crypto
 
 import (
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/tendermint/go-amino"
 	"math/big"
+	"runtime"
 )
 
 // Polynomial represents a polynomial in the polynomial ring Z_p[x], where Z_p is the ring of
@@ -56,6 +59,17 @@ func (p Polynomial) Degree() int {
 	return len(trim(p.Coeffs)) - 1
 }
 
+// Hash returns a SHA-256 hash of the polynomial's ring modulus and coefficients. Trailing zero
+// coefficients are ignored, i.e. the hash only depends on the polynomial's value.
+func (p Polynomial) Hash() []byte {
+	sha := sha256.New()
+	writeLengthPrefixed(sha, p.ZModPr.Modulus.Bytes())
+	for _, coeff := range trim(p.Coeffs) {
+		writeLengthPrefixed(sha, coeff.Bytes())
+	}
+	return sha.Sum(nil)
+}
+
 // String returns a string representation of this polynomial.
 func (p Polynomial) String() string {
 	polyMax := len(p.Coeffs) - 1
@@ -75,7 +89,7 @@ func (p Polynomial) String() string {
 // and the new polynomial's coefficients are new Int instances, i.e. changes in the input
 // polynomials do not affect the new polynomial and vice versa.
 func (p Polynomial) Add(other Polynomial) Polynomial {
-	if p.ZModPr.Modulus != other.ZModPr.Modulus {
+	if !p.ZModPr.Equal(other.ZModPr) {
 		panic("Cannot add two polynomials in different rings.")
 	}
 	maxIndex := len(p.Coeffs)
@@ -112,23 +126,111 @@ func (p Polynomial) MulScalar(scalar *big.Int) Polynomial {
 // Mul multiplies two Polynomials together and returns the resulting Polynomial.
 // The input polynomials are not modified and the new polynomial's coefficients are new
 // Int instances, i.e. changes in the input polynomials do not affect the new polynomial and vice
-// versa.
+// versa. Long polynomials are multiplied with Karatsuba's method or, if the ring allows it, with
+// the number-theoretic transform.
 func (p Polynomial) Mul(other Polynomial) Polynomial {
-	if p.ZModPr.Modulus != other.ZModPr.Modulus {
+	if !p.ZModPr.Equal(other.ZModPr) {
 		panic("Cannot multiply two polynomials in different rings.")
 	}
-	newDegree := p.Degree() + other.Degree()
-	newCoeffs := make([]*big.Int, newDegree+1) // +1 for the constant term
-	for i, thisCoeff := range p.Coeffs {
-		for j, otherCoeff := range other.Coeffs {
-			tmp := p.ZModPr.Mul(thisCoeff, otherCoeff)
-			if newCoeffs[i+j] == nil {
-				newCoeffs[i+j] = big.NewInt(0)
-			}
-			newCoeffs[i+j] = p.ZModPr.Add(newCoeffs[i+j], tmp)
+	return NewPolynomial(mulCoeffs(trim(p.Coeffs), trim(other.Coeffs), p.ZModPr), p.ZModPr)
+}
+
+// Evaluate evaluates this polynomial at x with Horner's method and returns the result, which is
+// an element of the polynomial's ring.
+func (p Polynomial) Evaluate(x *big.Int) *big.Int {
+	result := big.NewInt(0)
+	for i := len(p.Coeffs) - 1; i >= 0; i-- {
+		result = p.ZModPr.Add(p.ZModPr.Mul(result, x), p.Coeffs[i])
+	}
+	return result
+}
+
+// FromRoots creates the monic polynomial (X - roots[0]) * ... * (X - roots[n]) in the given ring,
+// i.e. the credential polynomial of the given credentials. The polynomial of no roots is 1. The
+// linear factors are multiplied in a product tree, which is much faster than including the roots
+// one by one for many roots.
+func FromRoots(roots []*big.Int, zModPr ZModPrime) Polynomial {
+	return NewPolynomial(productOfLinearFactors(roots, zModPr, runtime.NumCPU()), zModPr)
+}
+
+// DivideByRoot divides this polynomial by (X - u) with synthetic division and returns the quotient
+// and the remainder, which is equal to the evaluation of this polynomial at u. The division is
+// exact, i.e. the remainder is 0, if and only if u is a root of this polynomial. This polynomial
+// is not modified.
+func (p Polynomial) DivideByRoot(u *big.Int) (Polynomial, *big.Int) {
+	coeffs := trim(p.Coeffs)
+	if len(coeffs) == 1 {
+		return NewPolynomial([]*big.Int{big.NewInt(0)}, p.ZModPr), new(big.Int).Set(coeffs[0])
+	}
+	quotient := make([]*big.Int, len(coeffs)-1)
+	remainder := big.NewInt(0)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		remainder = p.ZModPr.Add(p.ZModPr.Mul(remainder, u), coeffs[i])
+		if i > 0 {
+			quotient[i-1] = remainder
 		}
 	}
-	return NewPolynomial(newCoeffs, p.ZModPr)
+	return NewPolynomial(quotient, p.ZModPr), remainder
+}
+
+// Derivative returns the formal derivative of this polynomial. This polynomial is not modified.
+func (p Polynomial) Derivative() Polynomial {
+	coeffs := trim(p.Coeffs)
+	if len(coeffs) == 1 {
+		return NewPolynomial([]*big.Int{big.NewInt(0)}, p.ZModPr)
+	}
+	newCoeffs := make([]*big.Int, len(coeffs)-1)
+	for i := 1; i < len(coeffs); i++ {
+		newCoeffs[i-1] = p.ZModPr.Mul(coeffs[i], big.NewInt(int64(i)))
+	}
+	return NewPolynomial(trim(newCoeffs), p.ZModPr)
+}
+
+// Equal checks if this polynomial and the given polynomial are equal, i.e. if their rings have
+// the same modulus and if their coefficients are equal. Trailing zero coefficients are ignored.
+func (p Polynomial) Equal(other Polynomial) bool {
+	if !p.ZModPr.Equal(other.ZModPr) {
+		return false
+	}
+	coeffs, otherCoeffs := trim(p.Coeffs), trim(other.Coeffs)
+	if len(coeffs) != len(otherCoeffs) {
+		return false
+	}
+	for i := range coeffs {
+		if coeffs[i].Cmp(otherCoeffs[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// CoeffStrings returns the decimal representations of this polynomial's coefficients ordered
+// ascending by degree.
+func (p Polynomial) CoeffStrings() []string {
+	strs := make([]string, len(p.Coeffs))
+	for i, coeff := range p.Coeffs {
+		strs[i] = coeff.String()
+	}
+	return strs
+}
+
+// NewPolynomialFromStrings creates a polynomial in the given ring from the decimal
+// representations of its coefficients ordered ascending by degree, e.g. as returned by
+// CoeffStrings. Returns an error if a coefficient is not an element of the ring.
+func NewPolynomialFromStrings(strs []string, zModPr ZModPrime) (Polynomial, error) {
+	if len(strs) == 0 {
+		return Polynomial{}, errors.New("a polynomial must have at least one coefficient")
+	}
+	coeffs := make([]*big.Int, len(strs))
+	for i, s := range strs {
+		coeff, ok := new(big.Int).SetString(s, 10)
+		if !ok || !zModPr.Contains(coeff) {
+			return Polynomial{}, fmt.Errorf("coefficient %d ('%s') is not an element of Z_%s", i,
+				s, zModPr.Modulus)
+		}
+		coeffs[i] = coeff
+	}
+	return NewPolynomial(coeffs, zModPr), nil
 }
 
 // IncludeCredential includes the given credential u into this polynomial and returns the resulting
@@ -136,9 +238,19 @@ func (p Polynomial) Mul(other Polynomial) Polynomial {
 // polynomial is not modified and the new polynomial's coefficients are new Int instances, i.e.
 // changes in the input polynomial do not affect the new polynomial and vice versa.
 func (p Polynomial) IncludeCredential(u *big.Int) Polynomial {
-	coeffs := []*big.Int{p.ZModPr.AdditiveInvert(u), big.NewInt(1)}
-	p2 := NewPolynomial(coeffs, p.ZModPr)
-	return p.Mul(p2)
+	coeffs := trim(p.Coeffs)
+	newCoeffs := make([]*big.Int, len(coeffs)+1)
+	// The coefficient of X^i is a_(i-1) - u * a_i.
+	for i := range coeffs {
+		c := new(big.Int).Mul(u, coeffs[i])
+		c.Neg(c)
+		if i > 0 {
+			c.Add(c, coeffs[i-1])
+		}
+		newCoeffs[i] = c.Mod(c, p.ZModPr.Modulus)
+	}
+	newCoeffs[len(coeffs)] = new(big.Int).Set(coeffs[len(coeffs)-1])
+	return NewPolynomial(newCoeffs, p.ZModPr)
 }
 
 // PolynomialDTO wraps the coefficients of a Polynomial into the amino serializable Int class.
@@ -150,12 +262,15 @@ type polynomialDTO struct {
 func (p Polynomial) MarshalAmino() (string, error) {
 	dto := polynomialDTO{}
 	p.wrapInDTO(&dto)
-	return string(amino.MustMarshalBinaryBare(dto)), nil
+	bz, err := amino.MarshalBinaryBare(dto)
+	return string(bz), err
 }
 
 func (p *Polynomial) UnmarshalAmino(bytes []byte) error {
 	var dto polynomialDTO
-	amino.MustUnmarshalBinaryBare(bytes, &dto)
+	if err := amino.UnmarshalBinaryBare(bytes, &dto); err != nil {
+		return err
+	}
 	p.unwrapDTO(dto)
 	return nil
 }
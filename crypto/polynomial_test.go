@@ -0,0 +1,155 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPolynomialEvaluate(t *testing.T) {
+	q, _ := new(big.Int).SetString(qTest, 10)
+	zq := NewZModPrime(q)
+
+	// 3 + 2x + x^2
+	poly := NewPolynomial([]*big.Int{big.NewInt(3), big.NewInt(2), big.NewInt(1)}, zq)
+	if poly.Evaluate(big.NewInt(2)).Cmp(big.NewInt(11)) != 0 {
+		t.Error("3 + 2x + x^2 must evaluate to 11 at x = 2")
+	}
+	if poly.Evaluate(new(big.Int).Sub(q, big.NewInt(1))).Cmp(big.NewInt(2)) != 0 {
+		t.Error("3 + 2x + x^2 must evaluate to 2 at x = -1")
+	}
+
+	u1, u2 := zq.RandomElement(), zq.RandomElement()
+	poly = NewPolynomial([]*big.Int{big.NewInt(1)}, zq).IncludeCredential(u1).IncludeCredential(u2)
+	if poly.Evaluate(u1).Sign() != 0 || poly.Evaluate(u2).Sign() != 0 {
+		t.Error("included credentials must be roots of the polynomial")
+	}
+	if poly.Evaluate(zq.RandomElement()).Sign() == 0 {
+		t.Error("a random element must not be a root of the polynomial")
+	}
+}
+
+func TestPolynomialFromRootsAndDivideByRoot(t *testing.T) {
+	q, _ := new(big.Int).SetString(qTest, 10)
+	zq := NewZModPrime(q)
+
+	roots := []*big.Int{zq.RandomElement(), zq.RandomElement(), zq.RandomElement()}
+	poly := FromRoots(roots, zq)
+	expected := NewPolynomial([]*big.Int{big.NewInt(1)}, zq)
+	for _, u := range roots {
+		expected = expected.IncludeCredential(u)
+	}
+	if !poly.Equal(expected) {
+		t.Error("FromRoots must be equal to including the roots one by one")
+	}
+
+	quotient, remainder := poly.DivideByRoot(roots[1])
+	if remainder.Sign() != 0 {
+		t.Error("dividing by a root must leave no remainder")
+	}
+	if !quotient.Equal(FromRoots([]*big.Int{roots[0], roots[2]}, zq)) {
+		t.Error("quotient must be the polynomial of the remaining roots")
+	}
+
+	x := zq.RandomElement()
+	quotient, remainder = poly.DivideByRoot(x)
+	if remainder.Cmp(poly.Evaluate(x)) != 0 {
+		t.Error("remainder must be the evaluation at x")
+	}
+	// quotient * (X - x) + remainder = poly
+	linear := NewPolynomial([]*big.Int{zq.AdditiveInvert(x), big.NewInt(1)}, zq)
+	if !quotient.Mul(linear).Add(NewPolynomial([]*big.Int{remainder}, zq)).Equal(poly) {
+		t.Error("quotient * (X - x) + remainder must be equal to the polynomial")
+	}
+}
+
+func TestPolynomialDerivative(t *testing.T) {
+	q, _ := new(big.Int).SetString(qTest, 10)
+	zq := NewZModPrime(q)
+
+	// 3 + 2x + 5x^2 + x^3
+	poly := NewPolynomial([]*big.Int{big.NewInt(3), big.NewInt(2), big.NewInt(5), big.NewInt(1)}, zq)
+	// 2 + 10x + 3x^2
+	expected := NewPolynomial([]*big.Int{big.NewInt(2), big.NewInt(10), big.NewInt(3)}, zq)
+	if !poly.Derivative().Equal(expected) {
+		t.Errorf("derivative must be %s but was %s", expected, poly.Derivative())
+	}
+	constant := NewPolynomial([]*big.Int{big.NewInt(3)}, zq)
+	if constant.Derivative().Degree() != 0 || constant.Derivative().Coeffs[0].Sign() != 0 {
+		t.Error("derivative of a constant must be 0")
+	}
+	// A double root is a root of the derivative.
+	u := zq.RandomElement()
+	if FromRoots([]*big.Int{u, u}, zq).Derivative().Evaluate(u).Sign() != 0 {
+		t.Error("a double root must be a root of the derivative")
+	}
+}
+
+func TestPolynomialEqual(t *testing.T) {
+	q, _ := new(big.Int).SetString(qTest, 10)
+	zq := NewZModPrime(q)
+
+	poly := NewPolynomial([]*big.Int{big.NewInt(3), big.NewInt(2)}, zq)
+	padded := NewPolynomial([]*big.Int{big.NewInt(3), big.NewInt(2), big.NewInt(0)}, zq)
+	if !poly.Equal(padded) {
+		t.Error("trailing zero coefficients must be ignored")
+	}
+	other := NewPolynomial([]*big.Int{big.NewInt(3), big.NewInt(1)}, zq)
+	if poly.Equal(other) {
+		t.Error("polynomials with different coefficients must not be equal")
+	}
+	otherRing := NewPolynomial([]*big.Int{big.NewInt(3), big.NewInt(2)}, NewZModPrime(big.NewInt(11)))
+	if poly.Equal(otherRing) {
+		t.Error("polynomials in different rings must not be equal")
+	}
+}
+
+func TestPolynomialDecodedSeparately(t *testing.T) {
+	q, _ := new(big.Int).SetString(qTest, 10)
+	zq := NewZModPrime(q)
+	poly := FromRoots([]*big.Int{zq.RandomElement(), zq.RandomElement()}, zq)
+
+	bytes, err := poly.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p1, p2 Polynomial
+	if err := p1.UnmarshalJSON(bytes); err != nil {
+		t.Fatal(err)
+	}
+	if err := p2.UnmarshalJSON(bytes); err != nil {
+		t.Fatal(err)
+	}
+	if !p1.Equal(poly) {
+		t.Error("decoded polynomial must be equal to the original")
+	}
+	// Must not panic although the moduli are different pointers.
+	if !p1.Add(p2).Equal(poly.MulScalar(big.NewInt(2))) {
+		t.Error("p + p must be equal to 2p")
+	}
+	if !p1.Mul(p2).Equal(poly.Mul(poly)) {
+		t.Error("p * p must be equal to the square of p")
+	}
+}
+
+func TestPolynomialFromStrings(t *testing.T) {
+	q, _ := new(big.Int).SetString(qTest, 10)
+	zq := NewZModPrime(q)
+	poly := FromRoots([]*big.Int{zq.RandomElement(), zq.RandomElement()}, zq)
+
+	decoded, err := NewPolynomialFromStrings(poly.CoeffStrings(), zq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Equal(poly) {
+		t.Error("polynomial decoded from its coefficient strings must be equal to the original")
+	}
+	if _, err := NewPolynomialFromStrings([]string{"1", q.String()}, zq); err == nil {
+		t.Error("coefficients not in the ring must be rejected")
+	}
+	if _, err := NewPolynomialFromStrings([]string{"1", "x"}, zq); err == nil {
+		t.Error("coefficients that are not numbers must be rejected")
+	}
+	if _, err := NewPolynomialFromStrings(nil, zq); err == nil {
+		t.Error("polynomials without coefficients must be rejected")
+	}
+}
@@ -1,8 +1,9 @@
 package crypto
 
 import (
-	"crypto/sha256"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/tendermint/go-amino"
 	"math/big"
 	"time"
@@ -10,28 +11,41 @@ import (
 
 // PreimageEqualityProofSystem is used to proof equality of preimages. In the case of the UEP voting
 // protocol it is used to proof that a voter's private credential beta was used in generating
-//commitment d and election credential uHat.
+// commitment d and election credential uHat.
+// It is safe to generate and verify proofs with the same instance from multiple goroutines.
 type PreimageEqualityProofSystem struct {
 	HHat *big.Int // Election generator used to generate the voter's election credential
 	// Commitment scheme used to commit to the voter's private credentials alpha and beta.
 	CommScheme PedersenCommitmentScheme
-	gStarModPr GStarModPrime
-	zModPr     ZModPrime
+	// Context is absorbed into the challenge of every proof. It binds the proofs to e.g. a chain
+	// and an election. Prover and verifier must use the same context.
+	Context []byte
+	group   Group
+	zModPr  ZModPrime
 }
 
 // NewPreimageEqualityProofSystem sets up a new instance of the proof system. Parameter hHat is the
 // election generator used to generate the voter's election credential uHat. The parameter
 // commScheme is the commitment scheme used to commit to the voter's private credentials alpha and
-// beta.
+// beta. Returns an error if hHat is not a generator of the scheme's group or if the scheme does
+// not have two message generators.
 func NewPreimageEqualityProofSystem(hHat *big.Int,
-	commScheme PedersenCommitmentScheme) PreimageEqualityProofSystem {
+	commScheme PedersenCommitmentScheme) (PreimageEqualityProofSystem, error) {
 
+	if len(commScheme.Hm) != 2 {
+		return PreimageEqualityProofSystem{}, fmt.Errorf("the commitment scheme must have two "+
+			"message generators but has %d", len(commScheme.Hm))
+	}
+	if commScheme.G == nil || !commScheme.G.IsGenerator(hHat) {
+		return PreimageEqualityProofSystem{}, errors.New("the election generator is not a " +
+			"generator of the commitment scheme's group")
+	}
 	return PreimageEqualityProofSystem{
 		HHat:       hHat,
 		CommScheme: commScheme,
-		gStarModPr: commScheme.G,
+		group:      commScheme.G,
 		zModPr:     commScheme.G.ZModOrder(),
-	}
+	}, nil
 }
 
 // PreimageEqualityProof represents a transcript of a preimage equality proof.
@@ -54,8 +68,10 @@ func (ps *PreimageEqualityProofSystem) Generate(voter Voter, commToAandB *big.In
 	rb := ps.zModPr.RandomElement()
 	rs := ps.zModPr.RandomElement()
 
-	comm1 := ps.CommScheme.Commit(rs, ra, rb)
-	comm2 := ps.gStarModPr.Exp(ps.HHat, rb)
+	comm1 := ps.CommScheme.commit(rs, ra, rb)
+	var hHatExp product
+	hHatExp.mulFixed(ps.HHat, rb)
+	comm2 := hHatExp.eval(ps.group)
 
 	ch := ps.generateChallenge(commToAandB, uHat, []*big.Int{comm1, comm2}, vote)
 
@@ -72,40 +88,87 @@ func (ps *PreimageEqualityProofSystem) Generate(voter Voter, commToAandB *big.In
 	}
 }
 
-// Verify verifies the given preimage equality proof transcript.
+// ValidateProof checks that the commitments of the given transcript are elements of G_q and that
+// the responses are elements of Z_q.
+func (ps *PreimageEqualityProofSystem) ValidateProof(proof PreimageEqualityProof) error {
+	if err := checkElement(ps.group, "comm", proof.Comm); err != nil {
+		return err
+	}
+	if err := checkElement(ps.group, "comm_h_hat", proof.CommHHat); err != nil {
+		return err
+	}
+	responses := transcriptArray{"resp", []*big.Int{proof.RespA, proof.RespB, proof.RespS}, 3}
+	return responses.checkRingElements(ps.zModPr)
+}
+
+// Verify verifies the given preimage equality proof transcript. The transcript, the commitment to
+// a and b and the election credential are validated first. If they are malformed, an error is
+// returned and no verification takes place.
 func (ps *PreimageEqualityProofSystem) Verify(proof PreimageEqualityProof, commToAandB *big.Int,
-	uHat *big.Int, vote string) bool {
+	uHat *big.Int, vote string) (bool, error) {
 
 	defer LogExecutionTime(time.Now(), "preimage equality proof verification")
 
+	eqs, err := ps.Equations(proof, commToAandB, uHat, vote)
+	if err != nil {
+		return false, err
+	}
+	return eqs.Hold(), nil
+}
+
+// Equations validates the given proof transcript and statement like Verify and returns the
+// equations that hold if the proof is valid. They can be verified together with the equations of
+// other proofs with a BatchVerifier.
+func (ps *PreimageEqualityProofSystem) Equations(proof PreimageEqualityProof,
+	commToAandB *big.Int, uHat *big.Int, vote string) (Equations, error) {
+
+	if err := checkElement(ps.group, "commitment to a and b", commToAandB); err != nil {
+		return nil, err
+	}
+	if err := checkElement(ps.group, "election credential", uHat); err != nil {
+		return nil, err
+	}
+	if err := ps.ValidateProof(proof); err != nil {
+		return nil, err
+	}
+
 	ch := ps.generateChallenge(commToAandB, uHat, []*big.Int{proof.Comm, proof.CommHHat}, vote)
 
-	res1 := make([]*big.Int, 2)
-	res1[0] = ps.CommScheme.Commit(proof.RespS, proof.RespA, proof.RespB)
-	res1[1] = ps.gStarModPr.Exp(ps.HHat, proof.RespB)
+	// Com(s, a, b) = comm * d^c
+	eq1 := newEquation(ps.group)
+	ps.CommScheme.commitTerms(&eq1.left, proof.RespS, proof.RespA, proof.RespB)
+	eq1.right.mulElement(proof.Comm)
+	eq1.right.mul(commToAandB, ch)
 
-	res2 := make([]*big.Int, 2)
-	res2[0] = ps.gStarModPr.Mul(proof.Comm, ps.gStarModPr.Exp(commToAandB, ch))
-	res2[1] = ps.gStarModPr.Mul(proof.CommHHat, ps.gStarModPr.Exp(uHat, ch))
+	// hHat^b = comm_h_hat * uHat^c
+	eq2 := newEquation(ps.group)
+	eq2.left.mulFixed(ps.HHat, proof.RespB)
+	eq2.right.mulElement(proof.CommHHat)
+	eq2.right.mul(uHat, ch)
 
-	return res1[0].Cmp(res2[0]) == 0 && res1[1].Cmp(res2[1]) == 0
+	return Equations{eq1, eq2}, nil
 }
 
+// generateChallenge derives the challenge from a transcript of the context, the commitment
+// scheme, the election generator, the statement (the commitment d and the election credential),
+// the prover's commitments and the vote.
 func (ps *PreimageEqualityProofSystem) generateChallenge(commToAandB *big.Int, uHat *big.Int,
 	commitments []*big.Int, vote string) *big.Int {
-	inputs := [][]*big.Int{{commToAandB, uHat}, commitments}
-	sha := sha256.New()
-	for _, c := range inputs {
-		for _, elem := range c {
-			sha.Write(elem.Bytes())
-		}
-	}
-	sha.Write([]byte(vote))
-	hash := sha.Sum(nil)
-	ch := new(big.Int).SetBytes(hash)
-	return ch.Mod(ch, ps.zModPr.Modulus)
+
+	t := NewTranscript(preimageEqualityProtocol)
+	t.AppendBytes("context", ps.Context)
+	t.AppendCommitmentScheme("comm_scheme", &ps.CommScheme)
+	t.AppendInt("h_hat", ps.HHat)
+	t.AppendInt("comm_a_b", commToAandB)
+	t.AppendInt("u_hat", uHat)
+	t.AppendInts("commitments", commitments)
+	t.AppendString("vote", vote)
+	return t.Challenge(ps.zModPr.Modulus)
 }
 
+// preimageEqualityProtocol is the protocol label of the pre-image equality proof's transcript.
+const preimageEqualityProtocol = "preimage-equality"
+
 // preimageEqualityProofDTO is required for Tendermint serialization and deserialization.
 type preimageEqualityProofDTO struct {
 	Comm     Int `json:"comm"`
@@ -118,12 +181,15 @@ type preimageEqualityProofDTO struct {
 func (p PreimageEqualityProof) MarshalAmino() (string, error) {
 	dto := preimageEqualityProofDTO{}
 	p.wrapInDTO(&dto)
-	return string(amino.MustMarshalBinaryBare(dto)), nil
+	bz, err := amino.MarshalBinaryBare(dto)
+	return string(bz), err
 }
 
 func (p *PreimageEqualityProof) UnmarshalAmino(bytes []byte) error {
 	var dto preimageEqualityProofDTO
-	amino.MustUnmarshalBinaryBare(bytes, &dto)
+	if err := amino.UnmarshalBinaryBare(bytes, &dto); err != nil {
+		return err
+	}
 	p.unwrapDTO(dto)
 	return nil
 }
@@ -10,7 +10,7 @@ func TestPreimgEqProofSystem(t *testing.T) {
 	q, _ := new(big.Int).SetString(qTest, 10)
 
 	gQ := NewGStarModPrime(p, q)
-	commQ := NewPedersenCommitmentScheme(gQ, gQ.RandomGenerator(),
+	commQ := NewPedersenCommitmentScheme(&gQ, gQ.RandomGenerator(),
 		[]*big.Int{gQ.RandomGenerator(), gQ.RandomGenerator()}) // comm_q can take two messages
 
 	hHat := gQ.RandomElement()
@@ -20,11 +20,31 @@ func TestPreimgEqProofSystem(t *testing.T) {
 
 	// commitment d
 	commToAandBRand := commQ.G.ZModOrder().RandomElement()
-	commToAandB := commQ.Commit(commToAandBRand, voter.A, voter.B)
+	commToAandB, err := commQ.Commit(commToAandBRand, voter.A, voter.B)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	ps := NewPreimageEqualityProofSystem(hHat, commQ)
+	ps, err := NewPreimageEqualityProofSystem(hHat, commQ)
+	if err != nil {
+		t.Fatal(err)
+	}
 	proof := ps.Generate(voter, commToAandB, commToAandBRand, uHat, "yes")
-	if !ps.Verify(proof, commToAandB, uHat, "yes") {
+	if v, err := ps.Verify(proof, commToAandB, uHat, "yes"); err != nil || !v {
 		t.Fail()
 	}
+	if err := ps.ValidateProof(proof); err != nil {
+		t.Error(err)
+	}
+	ps.Context = []byte("other election")
+	if v, _ := ps.Verify(proof, commToAandB, uHat, "yes"); v {
+		t.Error("proof must not verify in a different context")
+	}
+	proof.RespB = nil
+	if ps.ValidateProof(proof) == nil {
+		t.Error("proof with a missing response must be malformed")
+	}
+	if _, err := ps.Verify(proof, commToAandB, uHat, "yes"); err == nil {
+		t.Error("verification of a malformed proof must fail with an error")
+	}
 }
@@ -0,0 +1,225 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/tendermint/go-amino"
+	"math/big"
+)
+
+// Number of Miller-Rabin rounds used for primality tests. Go's implementation additionally applies
+// the Baillie-PSW test.
+const primalityRounds = 20
+
+// PrimeChain holds the chain of primes defining the groups of the UEP protocol. The group G_q has
+// prime order q and is a subgroup of Z*_p. The group G_p has prime order p and is a subgroup of
+// Z*_o. This requires p = a * q + 1 and o = k * p + 1 for some cofactors a and k.
+//
+// Next to the primes and cofactors the chain holds Pocklington witnesses w_p and w_o which allow
+// anyone to re-check the chain. For a prime r dividing n - 1 a Pocklington witness w satisfies
+// w^(n-1) = 1 (mod n) and gcd(w^((n-1)/r) - 1, n) = 1. This proves that every prime factor of n is
+// congruent to 1 modulo r. If r > sqrt(n) - 1, n is therefore proven to be prime given that r is
+// prime. This is always the case for o (because p > sqrt(o)) and for p if q has more than half of
+// p's bits. Otherwise, the witness for p complements the probabilistic primality test.
+type PrimeChain struct {
+	Q *big.Int // Order of G_q.
+	P *big.Int // Order of G_p and modulus of G_q.
+	O *big.Int // Modulus of G_p.
+	A *big.Int // Cofactor with p = a * q + 1.
+	K *big.Int // Cofactor with o = k * p + 1.
+	W *big.Int // Pocklington witness for p with respect to q.
+	V *big.Int // Pocklington witness for o with respect to p.
+}
+
+// NewPrimeChain creates a new chain from the given primes q, p and o. The cofactors and
+// witnesses are computed. Returns an error if the primes do not form a valid chain.
+func NewPrimeChain(q, p, o *big.Int) (PrimeChain, error) {
+	one := big.NewInt(1)
+	a, rem := new(big.Int).QuoRem(new(big.Int).Sub(p, one), q, new(big.Int))
+	if rem.Sign() != 0 {
+		return PrimeChain{}, errors.New("q does not divide p - 1")
+	}
+	k, rem := new(big.Int).QuoRem(new(big.Int).Sub(o, one), p, new(big.Int))
+	if rem.Sign() != 0 {
+		return PrimeChain{}, errors.New("p does not divide o - 1")
+	}
+	w, err := findPocklingtonWitness(p, q)
+	if err != nil {
+		return PrimeChain{}, fmt.Errorf("no witness for p found\n%v", err)
+	}
+	v, err := findPocklingtonWitness(o, p)
+	if err != nil {
+		return PrimeChain{}, fmt.Errorf("no witness for o found\n%v", err)
+	}
+	chain := PrimeChain{Q: q, P: p, O: o, A: a, K: k, W: w, V: v}
+	return chain, chain.Verify()
+}
+
+// GeneratePrimeChain searches a new chain of primes q, p = a * q + 1 and o = k * p + 1 with the
+// given bit lengths. The bit length of o must be greater than that of p and the bit length of p
+// greater than that of q. The bit length of o must be at most 2|p| - 2, which guarantees
+// p > sqrt(o) such that the Pocklington witness for o proves it to be prime.
+func GeneratePrimeChain(qBits, pBits, oBits int) (PrimeChain, error) {
+	if qBits < 2 || pBits <= qBits || oBits <= pBits {
+		return PrimeChain{}, fmt.Errorf("bit lengths must satisfy 1 < |q| < |p| < |o|, got "+
+			"%d, %d and %d", qBits, pBits, oBits)
+	}
+	if oBits > 2*pBits-2 {
+		return PrimeChain{}, fmt.Errorf("bit length of o must be at most 2|p| - 2 = %d such "+
+			"that p > sqrt(o), got %d", 2*pBits-2, oBits)
+	}
+	for {
+		q, err := rand.Prime(rand.Reader, qBits)
+		if err != nil {
+			return PrimeChain{}, err
+		}
+		// For some q (or p) there is no prime of the form a * q + 1 (or k * p + 1) with the given
+		// bit length. In that case we try again with a new q (or p).
+		p, ok := findPrimeWithFactor(q, pBits)
+		for ok {
+			var o *big.Int
+			if o, ok = findPrimeWithFactor(p, oBits); ok {
+				return NewPrimeChain(q, p, o)
+			}
+			p, ok = findPrimeWithFactor(q, pBits)
+		}
+	}
+}
+
+// findPrimeWithFactor searches a prime n = c * r + 1 with the given bit length where c is even.
+// The candidates are chosen randomly among all candidates with the given bit length. Returns false
+// if no prime was found after trying a number of candidates that makes finding a prime very
+// likely if there are enough candidates.
+func findPrimeWithFactor(r *big.Int, bits int) (*big.Int, bool) {
+	one := big.NewInt(1)
+	two := big.NewInt(2)
+	// All candidates n satisfy 2^(bits-1) <= n < 2^bits, i.e. cMin <= c < cMax.
+	nMin := new(big.Int).Lsh(one, uint(bits-1))
+	nMax := new(big.Int).Lsh(one, uint(bits))
+	cMin := new(big.Int).Div(new(big.Int).Sub(nMin, one), r)
+	cMin.Add(cMin, one)
+	cMax := new(big.Int).Div(new(big.Int).Sub(nMax, two), r)
+	cMax.Add(cMax, one)
+	cRange := new(big.Int).Sub(cMax, cMin)
+	if cRange.Sign() <= 0 {
+		return nil, false
+	}
+	// The probability of a candidate being prime is roughly 2 / ln(2^bits), since only even
+	// cofactors are considered. Trying 100 times the expected number of candidates leaves a
+	// negligible probability of missing an existing prime.
+	maxTries := 100 * bits
+	n := new(big.Int)
+	for i := 0; i < maxTries; i++ {
+		c := new(big.Int).Add(cMin, RandIntInRange(big.NewInt(0), cRange))
+		c.SetBit(c, 0, 0)
+		if c.Cmp(cMin) < 0 {
+			continue
+		}
+		n.Mul(c, r)
+		n.Add(n, one)
+		if n.ProbablyPrime(primalityRounds) {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// findPocklingtonWitness finds the smallest Pocklington witness w >= 2 for n with respect to the
+// prime r dividing n - 1.
+func findPocklingtonWitness(n, r *big.Int) (*big.Int, error) {
+	for w := big.NewInt(2); w.Cmp(n) < 0; w.Add(w, big.NewInt(1)) {
+		if isPocklingtonWitness(w, n, r) {
+			return w, nil
+		}
+		if w.Cmp(big.NewInt(1000)) > 0 {
+			// For a prime n almost every base is a witness.
+			break
+		}
+	}
+	return nil, errors.New("n is not prime")
+}
+
+// isPocklingtonWitness checks w^(n-1) = 1 (mod n) and gcd(w^((n-1)/r) - 1, n) = 1.
+func isPocklingtonWitness(w, n, r *big.Int) bool {
+	one := big.NewInt(1)
+	nMinusOne := new(big.Int).Sub(n, one)
+	if new(big.Int).Exp(w, nMinusOne, n).Cmp(one) != 0 {
+		return false
+	}
+	x := new(big.Int).Exp(w, new(big.Int).Div(nMinusOne, r), n)
+	x.Sub(x, one)
+	return new(big.Int).GCD(nil, nil, x, n).Cmp(one) == 0
+}
+
+// Verify checks the primality of q, p and o, the relations p = a * q + 1 and o = k * p + 1 and
+// the Pocklington witnesses of p and o.
+func (c PrimeChain) Verify() error {
+	for _, v := range []*big.Int{c.Q, c.P, c.O, c.A, c.K, c.W, c.V} {
+		if v == nil || v.Sign() <= 0 {
+			return errors.New("all values of the prime chain must be positive")
+		}
+	}
+	one := big.NewInt(1)
+	if new(big.Int).Add(new(big.Int).Mul(c.A, c.Q), one).Cmp(c.P) != 0 {
+		return errors.New("p is not equal to a * q + 1")
+	}
+	if new(big.Int).Add(new(big.Int).Mul(c.K, c.P), one).Cmp(c.O) != 0 {
+		return errors.New("o is not equal to k * p + 1")
+	}
+	if !c.Q.ProbablyPrime(primalityRounds) {
+		return errors.New("q is not prime")
+	}
+	if !isPocklingtonWitness(c.W, c.P, c.Q) || !c.P.ProbablyPrime(primalityRounds) {
+		return errors.New("p is not prime")
+	}
+	// Since p > sqrt(o) - 1 the witness proves o to be prime.
+	if !isPocklingtonWitness(c.V, c.O, c.P) ||
+		new(big.Int).Mul(c.P, c.P).Cmp(c.O) <= 0 {
+		return errors.New("o is not prime")
+	}
+	return nil
+}
+
+// Groups returns the groups G_p and G_q defined by this chain.
+func (c PrimeChain) Groups() (gP GStarModPrime, gQ GStarModPrime) {
+	return NewGStarModPrime(c.O, c.P), NewGStarModPrime(c.P, c.Q)
+}
+
+// String returns a string representation of this chain.
+func (c PrimeChain) String() string {
+	return fmt.Sprintf("PrimeChain: {\n\tq (%d bit)=%s,\n\tp (%d bit)=%s,\n\to (%d bit)=%s\n}",
+		c.Q.BitLen(), c.Q, c.P.BitLen(), c.P, c.O.BitLen(), c.O)
+}
+
+type primeChainDTO struct {
+	Q Int `json:"q"`
+	P Int `json:"p"`
+	O Int `json:"o"`
+	A Int `json:"a"`
+	K Int `json:"k"`
+	W Int `json:"w_p"`
+	V Int `json:"w_o"`
+}
+
+func (c PrimeChain) MarshalJSON() ([]byte, error) {
+	dto := primeChainDTO{NewInt(c.Q), NewInt(c.P), NewInt(c.O), NewInt(c.A), NewInt(c.K),
+		NewInt(c.W), NewInt(c.V)}
+	return json.Marshal(dto)
+}
+
+func (c *PrimeChain) UnmarshalJSON(bytes []byte) error {
+	var dto primeChainDTO
+	if err := amino.UnmarshalJSON(bytes, &dto); err != nil {
+		return err
+	}
+	c.Q = dto.Q.BigInt()
+	c.P = dto.P.BigInt()
+	c.O = dto.O.BigInt()
+	c.A = dto.A.BigInt()
+	c.K = dto.K.BigInt()
+	c.W = dto.W.BigInt()
+	c.V = dto.V.BigInt()
+	return nil
+}
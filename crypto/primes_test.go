@@ -0,0 +1,64 @@
+package crypto
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestGeneratePrimeChain(t *testing.T) {
+	chain, err := GeneratePrimeChain(40, 128, 136)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chain.Q.BitLen() != 40 || chain.P.BitLen() != 128 || chain.O.BitLen() != 136 {
+		t.Errorf("wrong bit lengths: %d, %d, %d", chain.Q.BitLen(), chain.P.BitLen(),
+			chain.O.BitLen())
+	}
+	if err := chain.Verify(); err != nil {
+		t.Error(err)
+	}
+
+	bz, err := json.Marshal(chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded PrimeChain
+	if err := json.Unmarshal(bz, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.Verify(); err != nil || decoded.O.Cmp(chain.O) != 0 {
+		t.Error("decoded chain must be equal to the generated chain")
+	}
+
+	decoded.A.Add(decoded.A, big.NewInt(2))
+	if decoded.Verify() == nil {
+		t.Error("chain with a wrong cofactor must not verify")
+	}
+}
+
+func TestGeneratePrimeChainRejectsInvalidBitLengths(t *testing.T) {
+	for _, bits := range [][3]int{{1, 128, 136}, {40, 40, 136}, {40, 128, 128}, {40, 128, 255},
+		{40, 128, 256}} {
+		if _, err := GeneratePrimeChain(bits[0], bits[1], bits[2]); err == nil {
+			t.Errorf("bit lengths %v must be rejected", bits)
+		}
+	}
+}
+
+func TestNewPrimeChain(t *testing.T) {
+	o, _ := new(big.Int).SetString(oTest, 10)
+	p, _ := new(big.Int).SetString(pTest, 10)
+	q, _ := new(big.Int).SetString(qTest, 10)
+
+	chain, err := NewPrimeChain(q, p, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chain.K.Cmp(big.NewInt(980)) != 0 {
+		t.Errorf("expected cofactor k = 980, got %s", chain.K)
+	}
+	if _, err := NewPrimeChain(q, o, p); err == nil {
+		t.Error("primes in the wrong order must not form a chain")
+	}
+}
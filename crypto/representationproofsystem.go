@@ -0,0 +1,189 @@
+package crypto
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/tendermint/go-amino"
+	"math/big"
+	"time"
+)
+
+// RepresentationProofSystem is used to prove knowledge of a voter's private credentials alpha and
+// beta, i.e. of the representation u = h1^alpha h2^beta of the public credential u with respect to
+// the message generators of the commitment scheme. A voter uses it to prove that they own a
+// registered credential, e.g. when replacing it with a new one.
+// It is safe to generate and verify proofs with the same instance from multiple goroutines.
+type RepresentationProofSystem struct {
+	// Commitment scheme whose message generators h1 and h2 the public credentials are built with.
+	CommScheme PedersenCommitmentScheme
+	// Context is absorbed into the challenge of every proof. It binds the proofs to e.g. a chain
+	// and an election. Prover and verifier must use the same context.
+	Context []byte
+	group   Group
+	zModPr  ZModPrime
+}
+
+// NewRepresentationProofSystem sets up a new instance of the proof system. The parameter
+// commScheme is the commitment scheme the voters' public credentials are built with. Returns an
+// error if the scheme does not have two message generators.
+func NewRepresentationProofSystem(commScheme PedersenCommitmentScheme) (RepresentationProofSystem,
+	error) {
+
+	if len(commScheme.Hm) != 2 {
+		return RepresentationProofSystem{}, fmt.Errorf("the commitment scheme must have two "+
+			"message generators but has %d", len(commScheme.Hm))
+	}
+	return RepresentationProofSystem{
+		CommScheme: commScheme,
+		group:      commScheme.G,
+		zModPr:     commScheme.G.ZModOrder(),
+	}, nil
+}
+
+// RepresentationProof represents a transcript of a proof of knowledge of a representation.
+type RepresentationProof struct {
+	Comm  *big.Int
+	RespA *big.Int
+	RespB *big.Int
+}
+
+// Generate generates a proof of knowledge of the voter's private credentials for the voter's
+// public credential. The message is absorbed into the challenge such that the proof cannot be
+// reused for another message, e.g. for the replacement of the credential with another one.
+func (ps *RepresentationProofSystem) Generate(voter Voter, message []byte) RepresentationProof {
+	defer LogExecutionTime(time.Now(), "representation proof generation")
+
+	ra := ps.zModPr.RandomElement()
+	rb := ps.zModPr.RandomElement()
+
+	var t product
+	ps.CommScheme.messageTerms(&t, ra, rb)
+	comm := t.eval(ps.group)
+
+	ch := ps.generateChallenge(voter.U, comm, message)
+
+	return RepresentationProof{
+		Comm:  comm,
+		RespA: ps.zModPr.Add(ra, ps.zModPr.Mul(voter.A, ch)),
+		RespB: ps.zModPr.Add(rb, ps.zModPr.Mul(voter.B, ch)),
+	}
+}
+
+// ValidateProof checks that the commitment of the given transcript is an element of G_q and that
+// the responses are elements of Z_q.
+func (ps *RepresentationProofSystem) ValidateProof(proof RepresentationProof) error {
+	if err := checkElement(ps.group, "comm", proof.Comm); err != nil {
+		return err
+	}
+	responses := transcriptArray{"resp", []*big.Int{proof.RespA, proof.RespB}, 2}
+	return responses.checkRingElements(ps.zModPr)
+}
+
+// Verify verifies the given proof transcript for the public credential u and the message. The
+// transcript and the credential are validated first. If they are malformed, an error is returned
+// and no verification takes place.
+func (ps *RepresentationProofSystem) Verify(proof RepresentationProof, u *big.Int,
+	message []byte) (bool, error) {
+
+	defer LogExecutionTime(time.Now(), "representation proof verification")
+
+	eqs, err := ps.Equations(proof, u, message)
+	if err != nil {
+		return false, err
+	}
+	return eqs.Hold(), nil
+}
+
+// Equations validates the given proof transcript and statement like Verify and returns the
+// equations that hold if the proof is valid. They can be verified together with the equations of
+// other proofs with a BatchVerifier.
+func (ps *RepresentationProofSystem) Equations(proof RepresentationProof, u *big.Int,
+	message []byte) (Equations, error) {
+
+	if err := checkElement(ps.group, "public credential", u); err != nil {
+		return nil, err
+	}
+	if err := ps.ValidateProof(proof); err != nil {
+		return nil, err
+	}
+
+	ch := ps.generateChallenge(u, proof.Comm, message)
+
+	// h1^a * h2^b = comm * u^c
+	eq := newEquation(ps.group)
+	ps.CommScheme.messageTerms(&eq.left, proof.RespA, proof.RespB)
+	eq.right.mulElement(proof.Comm)
+	eq.right.mul(u, ch)
+
+	return Equations{eq}, nil
+}
+
+// generateChallenge derives the challenge from a transcript of the context, the commitment
+// scheme, the statement (the public credential), the prover's commitment and the message.
+func (ps *RepresentationProofSystem) generateChallenge(u *big.Int, comm *big.Int,
+	message []byte) *big.Int {
+
+	t := NewTranscript(representationProtocol)
+	t.AppendBytes("context", ps.Context)
+	t.AppendCommitmentScheme("comm_scheme", &ps.CommScheme)
+	t.AppendInt("u", u)
+	t.AppendInt("comm", comm)
+	t.AppendBytes("message", message)
+	return t.Challenge(ps.zModPr.Modulus)
+}
+
+// representationProtocol is the protocol label of the representation proof's transcript.
+const representationProtocol = "representation"
+
+// representationProofDTO is required for Tendermint serialization and deserialization.
+type representationProofDTO struct {
+	Comm  Int `json:"comm"`
+	RespA Int `json:"resp_a"`
+	RespB Int `json:"resp_b"`
+}
+
+func (p RepresentationProof) MarshalAmino() (string, error) {
+	bz, err := amino.MarshalBinaryBare(p.wrapInDTO())
+	return string(bz), err
+}
+
+func (p *RepresentationProof) UnmarshalAmino(bytes []byte) error {
+	var dto representationProofDTO
+	if err := amino.UnmarshalBinaryBare(bytes, &dto); err != nil {
+		return err
+	}
+	p.unwrapDTO(dto)
+	return nil
+}
+
+func (p RepresentationProof) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.wrapInDTO())
+}
+
+func (p *RepresentationProof) UnmarshalJSON(bytes []byte) error {
+	var dto representationProofDTO
+	if err := amino.UnmarshalJSON(bytes, &dto); err != nil {
+		return err
+	}
+	p.unwrapDTO(dto)
+	return nil
+}
+
+func (p RepresentationProof) wrapInDTO() representationProofDTO {
+	return representationProofDTO{
+		Comm:  NewInt(p.Comm),
+		RespA: NewInt(p.RespA),
+		RespB: NewInt(p.RespB),
+	}
+}
+
+func (p *RepresentationProof) unwrapDTO(dto representationProofDTO) {
+	p.Comm = dto.Comm.BigInt()
+	p.RespA = dto.RespA.BigInt()
+	p.RespB = dto.RespB.BigInt()
+}
+
+func (p RepresentationProof) String() string {
+	return fmt.Sprintf("RepresentationProof: {comm: %s, resp_a: %s, resp_b: %s}", p.Comm,
+		p.RespA, p.RespB)
+}
@@ -0,0 +1,46 @@
+package crypto
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRepresentationProofSystem(t *testing.T) {
+	_, commQ := newTestSchemes()
+
+	voter := GenerateNewVoter(commQ)
+	ps, err := NewRepresentationProofSystem(commQ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps.Context = []byte("election")
+	proof := ps.Generate(voter, []byte("replace"))
+	if v, err := ps.Verify(proof, voter.U, []byte("replace")); err != nil || !v {
+		t.Fatal("valid proof must verify")
+	}
+	if v, _ := ps.Verify(proof, voter.U, []byte("other replacement")); v {
+		t.Error("proof must not verify for a different message")
+	}
+	if v, _ := ps.Verify(proof, GenerateNewVoter(commQ).U, []byte("replace")); v {
+		t.Error("proof must not verify for another credential")
+	}
+	bz, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded RepresentationProof
+	if err := json.Unmarshal(bz, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := ps.Verify(decoded, voter.U, []byte("replace")); err != nil || !v {
+		t.Error("decoded proof must verify")
+	}
+	ps.Context = []byte("other election")
+	if v, _ := ps.Verify(proof, voter.U, []byte("replace")); v {
+		t.Error("proof must not verify in a different context")
+	}
+	proof.RespA = nil
+	if _, err := ps.Verify(proof, voter.U, []byte("replace")); err == nil {
+		t.Error("verification of a malformed proof must fail with an error")
+	}
+}
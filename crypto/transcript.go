@@ -0,0 +1,81 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"hash"
+	"math/big"
+)
+
+// TranscriptVersion is the version of the Fiat-Shamir transcripts from which the challenges of all
+// proof systems are derived. Proofs created with a different version do not verify. Version 0
+// denotes proofs whose challenges were derived by concatenating the plain bytes of their inputs.
+const TranscriptVersion uint32 = 1
+
+// transcriptDomain separates transcripts from other uses of hashing in this package.
+const transcriptDomain = "up-voting-system/transcript"
+
+// Transcript is used to derive the challenge of a non-interactive zero-knowledge proof with the
+// Fiat-Shamir heuristic. The prover and the verifier absorb the same public parameters, the
+// statement and the prover's commitments in the same order. Every absorbed item is labelled and
+// length-prefixed such that different sequences of items can never result in the same encoding.
+// A transcript is bound to the protocol it is created for and to the TranscriptVersion.
+type Transcript struct {
+	sha hash.Hash
+}
+
+// NewTranscript creates a new transcript for the protocol with the given name.
+func NewTranscript(protocol string) *Transcript {
+	t := &Transcript{sha: sha256.New()}
+	writeLengthPrefixed(t.sha, []byte(transcriptDomain))
+	writeUint32(t.sha, TranscriptVersion)
+	t.AppendBytes("protocol", []byte(protocol))
+	return t
+}
+
+// AppendBytes absorbs the given data under the given label.
+func (t *Transcript) AppendBytes(label string, data []byte) {
+	writeLengthPrefixed(t.sha, []byte(label))
+	writeLengthPrefixed(t.sha, data)
+}
+
+// AppendString absorbs the given string under the given label.
+func (t *Transcript) AppendString(label string, s string) {
+	t.AppendBytes(label, []byte(s))
+}
+
+// AppendInt absorbs the given non-negative integer under the given label.
+func (t *Transcript) AppendInt(label string, v *big.Int) {
+	t.AppendBytes(label, v.Bytes())
+}
+
+// AppendInts absorbs the given list of non-negative integers under the given label. The number
+// of integers is absorbed as well.
+func (t *Transcript) AppendInts(label string, vs []*big.Int) {
+	writeLengthPrefixed(t.sha, []byte(label))
+	writeUint32(t.sha, uint32(len(vs)))
+	for _, v := range vs {
+		writeLengthPrefixed(t.sha, v.Bytes())
+	}
+}
+
+// AppendGroup absorbs the parameters of the given group under the given label.
+func (t *Transcript) AppendGroup(label string, g Group) {
+	t.AppendInts(label, g.Parameters())
+}
+
+// AppendCommitmentScheme absorbs the group and all generators of the given commitment scheme
+// under the given label.
+func (t *Transcript) AppendCommitmentScheme(label string, s *PedersenCommitmentScheme) {
+	t.AppendGroup(label, s.G)
+	t.AppendInts(label, append([]*big.Int{s.Hr}, s.Hm...))
+}
+
+// Challenge derives a challenge in Z_m, where m is the given modulus, from everything absorbed so
+// far. The digest of the transcript is expanded to 128 bits more than the modulus' bit length
+// before the reduction such that the challenge is close to uniformly distributed.
+func (t *Transcript) Challenge(modulus *big.Int) *big.Int {
+	byteLen := (modulus.BitLen() + 128 + 7) / 8
+	digest := t.sha.Sum(nil)
+	ch := new(big.Int).SetBytes(expandHash(byteLen, 0, []byte("challenge"), digest))
+	return ch.Mod(ch, modulus)
+}
@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestTranscriptChallenge(t *testing.T) {
+	q, _ := new(big.Int).SetString(qTest, 10)
+
+	challenge := func(protocol string, items ...[]byte) *big.Int {
+		tr := NewTranscript(protocol)
+		for _, item := range items {
+			tr.AppendBytes("item", item)
+		}
+		return tr.Challenge(q)
+	}
+	ch := challenge("protocol", []byte("ab"), []byte("c"))
+	if ch.Cmp(challenge("protocol", []byte("ab"), []byte("c"))) != 0 {
+		t.Error("the same transcript must result in the same challenge")
+	}
+	if ch.Sign() < 0 || ch.Cmp(q) >= 0 {
+		t.Error("challenge must be in Z_q")
+	}
+	// Shifting bytes between items must change the challenge.
+	if ch.Cmp(challenge("protocol", []byte("a"), []byte("bc"))) == 0 {
+		t.Error("transcripts with different items must result in different challenges")
+	}
+	if ch.Cmp(challenge("other protocol", []byte("ab"), []byte("c"))) == 0 {
+		t.Error("transcripts of different protocols must result in different challenges")
+	}
+
+	tr1 := NewTranscript("protocol")
+	tr1.AppendInts("list", []*big.Int{big.NewInt(1), big.NewInt(2)})
+	tr1.AppendInts("list", []*big.Int{big.NewInt(3)})
+	tr2 := NewTranscript("protocol")
+	tr2.AppendInts("list", []*big.Int{big.NewInt(1)})
+	tr2.AppendInts("list", []*big.Int{big.NewInt(2), big.NewInt(3)})
+	if tr1.Challenge(q).Cmp(tr2.Challenge(q)) == 0 {
+		t.Error("lists of different lengths must result in different challenges")
+	}
+
+	tr1 = NewTranscript("protocol")
+	tr1.AppendString("label", "value")
+	tr2 = NewTranscript("protocol")
+	tr2.AppendString("other label", "value")
+	if tr1.Challenge(q).Cmp(tr2.Challenge(q)) == 0 {
+		t.Error("items with different labels must result in different challenges")
+	}
+}
@@ -0,0 +1,78 @@
+package crypto
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// This file contains helpers to validate the values of proof transcripts before they are used in
+// a verification. A transcript received from an untrusted party must only contain group elements
+// of the expected groups and responses in the expected rings, and its arrays must have the lengths
+// expected by the proof system.
+
+// checkElement checks that the value is an element of the group g. The name is used to identify
+// the value in the returned error.
+func checkElement(g Group, name string, v *big.Int) error {
+	if v == nil {
+		return fmt.Errorf("%s is missing", name)
+	}
+	if !g.Contains(v) {
+		return fmt.Errorf("%s is not an element of the group", name)
+	}
+	return nil
+}
+
+// checkRingElement checks that the value is an element of the ring z, i.e. 0 <= v < modulus. The
+// name is used to identify the value in the returned error.
+func checkRingElement(z ZModPrime, name string, v *big.Int) error {
+	if v == nil {
+		return fmt.Errorf("%s is missing", name)
+	}
+	if !z.Contains(v) {
+		return fmt.Errorf("%s is not in the range [0, %s)", name, z.Modulus)
+	}
+	return nil
+}
+
+// transcriptArray is a named array of a proof transcript with its expected length.
+type transcriptArray struct {
+	name     string
+	values   []*big.Int
+	expected int
+}
+
+// checkLength checks that the array has the expected length.
+func (a transcriptArray) checkLength() error {
+	if len(a.values) != a.expected {
+		return fmt.Errorf("%s has length %d but must have length %d", a.name, len(a.values),
+			a.expected)
+	}
+	return nil
+}
+
+// checkElements checks the length of the array and that all its values are elements of group g.
+func (a transcriptArray) checkElements(g Group) error {
+	if err := a.checkLength(); err != nil {
+		return err
+	}
+	for i, v := range a.values {
+		if err := checkElement(g, fmt.Sprintf("%s[%d]", a.name, i), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRingElements checks the length of the array and that all its values are elements of the
+// ring z.
+func (a transcriptArray) checkRingElements(z ZModPrime) error {
+	if err := a.checkLength(); err != nil {
+		return err
+	}
+	for i, v := range a.values {
+		if err := checkRingElement(z, fmt.Sprintf("%s[%d]", a.name, i), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -15,10 +15,9 @@ type Voter struct {
 func GenerateNewVoter(commQ PedersenCommitmentScheme) Voter {
 	a := commQ.G.ZModOrder().RandomElement()
 	b := commQ.G.ZModOrder().RandomElement()
-	h1 := commQ.G.Exp(commQ.Hm[0], a)
-	h2 := commQ.G.Exp(commQ.Hm[1], b)
-	u := commQ.G.Mul(h1, h2)
-	return NewVoter(a, b, u)
+	var u product
+	commQ.messageTerms(&u, a, b)
+	return NewVoter(a, b, u.eval(commQ.G))
 }
 
 // NewVoter instantiates a new voter from the given credentials
@@ -1,6 +1,6 @@
 module github.com/csmuller/up-voting-system
 
-go 1.13
+go 1.15
 
 require (
 	github.com/cosmos/cosmos-sdk v0.37.8
@@ -0,0 +1,88 @@
+package pbb
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/csmuller/up-voting-system/pbb/internal/keeper"
+	"github.com/csmuller/up-voting-system/pbb/internal/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+// EndBlocker advances the election to the next phase as soon as the election schedule says that
+// the current phase is over. The block that ends a phase is the last block accepting messages of
+// that phase. Panics if the registration phase cannot be closed, which only happens if the stored
+// voter credentials are corrupt. Every node fails in the same block, which halts the chain. If no
+// voter credential is registered when the registration phase ends, the voting phase is skipped and
+// the election goes straight to the tally phase.
+func EndBlocker(ctx sdk.Context, k keeper.BulletinBoardKeeper) {
+	schedule := k.GetParams(ctx).Schedule
+	phase := k.GetElectionPhase(ctx)
+	if phase == types.PhaseRegistration &&
+		schedule.RegistrationEnded(ctx.BlockHeight(), ctx.BlockTime()) {
+		if hasVoterCredentials(ctx, k) {
+			if err := closeRegistration(ctx, k); err != nil {
+				// Without the credential polynomial and the election generator no ballot could
+				// be verified. Retrying in the next block would fail again.
+				panic(fmt.Sprintf("failed closing the registration phase at height %d: %s",
+					ctx.BlockHeight(), err.Error()))
+			}
+			phase = types.PhaseVoting
+		} else {
+			// The polynomial of an empty electorate is the constant 1, which has no roots. No
+			// ballot could ever be valid, so the voting phase is not opened.
+			ctx.Logger().Error("registration phase closed without voter credentials",
+				"height", ctx.BlockHeight())
+			ctx.EventManager().EmitEvent(sdk.NewEvent(EventTypeEmptyElectorate,
+				sdk.NewAttribute(AttributeKeyBlockHeight,
+					strconv.FormatInt(ctx.BlockHeight(), 10))))
+			phase = types.PhaseTally
+		}
+		setElectionPhase(ctx, k, phase)
+	}
+	if phase == types.PhaseVoting && schedule.VotingEnded(ctx.BlockHeight(), ctx.BlockTime()) {
+		phase = types.PhaseTally
+		setElectionPhase(ctx, k, phase)
+	}
+}
+
+// closeRegistration builds the final credential polynomial from the registered voter credentials
+// and publishes its hash. It then derives the election generator HHat from the hash of the
+// previous block and the polynomial's hash. The hash of the current block is not known yet at this
+// point.
+func closeRegistration(ctx sdk.Context, k keeper.BulletinBoardKeeper) sdk.Error {
+	params := k.GetParams(ctx)
+	poly, err := k.BuildCredentialPolynomial(ctx)
+	if err != nil {
+		return err
+	}
+	polyHash := poly.Hash()
+	ctx.EventManager().EmitEvent(sdk.NewEvent(EventTypeCredentialPolynomial,
+		sdk.NewAttribute(AttributeKeyPolynomialHash, cmn.HexBytes(polyHash).String()),
+		sdk.NewAttribute(AttributeKeyDegree, strconv.Itoa(poly.Degree()))))
+	blockHash := ctx.BlockHeader().LastBlockId.Hash
+	hHat := types.DeriveElectionGenerator(params.CommQ.G, blockHash, polyHash)
+	k.SetElectionGenerator(ctx, types.NewElectionGenerator(ctx.BlockHeight(), blockHash, polyHash,
+		hHat))
+	ctx.EventManager().EmitEvent(sdk.NewEvent(EventTypeElectionGenerator,
+		sdk.NewAttribute(AttributeKeyElectionGenerator, hHat.String())))
+	return nil
+}
+
+// hasVoterCredentials checks if at least one voter credential is registered.
+func hasVoterCredentials(ctx sdk.Context, k keeper.BulletinBoardKeeper) bool {
+	it := k.GetVoterCredentialsIterator(ctx)
+	defer it.Close()
+	return it.Valid()
+}
+
+func setElectionPhase(ctx sdk.Context, k keeper.BulletinBoardKeeper, phase types.ElectionPhase) {
+	k.SetElectionPhase(ctx, phase)
+	ctx.Logger().Info("election phase changed", "phase", phase.String(),
+		"height", ctx.BlockHeight())
+	ctx.EventManager().EmitEvent(sdk.NewEvent(EventTypeElectionPhase,
+		sdk.NewAttribute(AttributeKeyPhase, phase.String()),
+		sdk.NewAttribute(AttributeKeyBlockHeight, strconv.FormatInt(ctx.BlockHeight(), 10))))
+}
@@ -0,0 +1,87 @@
+package pbb
+
+import (
+	"testing"
+
+	"github.com/csmuller/up-voting-system/crypto"
+	"github.com/csmuller/up-voting-system/pbb/internal/types"
+)
+
+func TestRegistrationClosesWithLiveCredentials(t *testing.T) {
+	in := newTestInput(t, 4)
+	kept := in.register(t, in.voters[0])
+	revoked := in.register(t, in.voters[1])
+	replaced := in.register(t, in.voters[2])
+	replacement := in.newCredential()
+	res := in.handler(in.ctx, types.NewMsgRevokeVoterCredential(crypto.NewInt(revoked.U),
+		"not eligible", false, in.admin))
+	if !res.IsOK() {
+		t.Fatalf("revocation failed: %s", res.Log)
+	}
+	if res := in.handler(in.ctx, in.replacementMsg(t, replaced, replacement,
+		in.voters[2])); !res.IsOK() {
+		t.Fatalf("replacement failed: %s", res.Log)
+	}
+	if _, err := in.keeper.GetCredentialPolynomial(in.ctx); err == nil {
+		t.Error("polynomial must not be built during the registration phase")
+	}
+
+	EndBlocker(in.ctx.WithBlockHeight(testRegistrationEndHeight-1), in.keeper)
+	if phase := in.keeper.GetElectionPhase(in.ctx); phase != types.PhaseRegistration {
+		t.Fatalf("registration must not close before its end height, phase is %s", phase)
+	}
+	ctx := in.ctx.WithBlockHeight(testRegistrationEndHeight)
+	EndBlocker(ctx, in.keeper)
+	if phase := in.keeper.GetElectionPhase(ctx); phase != types.PhaseVoting {
+		t.Fatalf("registration must close at its end height, phase is %s", phase)
+	}
+
+	poly, err := in.keeper.GetCredentialPolynomial(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if poly.Degree() != 2 {
+		t.Errorf("polynomial must have the 2 live credentials as roots but has degree %d",
+			poly.Degree())
+	}
+	for _, live := range []crypto.Voter{kept, replacement} {
+		if poly.Evaluate(live.U).Sign() != 0 {
+			t.Errorf("live credential %s must be a root of the polynomial", live.U)
+		}
+	}
+	for _, dead := range []crypto.Voter{revoked, replaced} {
+		if poly.Evaluate(dead.U).Sign() == 0 {
+			t.Errorf("revoked or replaced credential %s must not be a root", dead.U)
+		}
+	}
+	gen, err := in.keeper.GetElectionGenerator(ctx)
+	if err != nil || gen == nil {
+		t.Fatalf("election generator must be derived: %v", err)
+	}
+	if err := gen.Verify(in.keeper.GetParams(ctx), poly); err != nil {
+		t.Error(err)
+	}
+	checkResult(t, in.handler(ctx, types.NewMsgPutVoterCredential(
+		crypto.NewInt(in.newCredential().U), in.voters[3])),
+		types.CredentialOutsideReg, "registration after the registration phase")
+}
+
+func TestRegistrationClosesWithoutCredentials(t *testing.T) {
+	in := newTestInput(t, 1)
+	in.ctx = in.ctx.WithBlockHeight(testRegistrationEndHeight)
+	EndBlocker(in.ctx, in.keeper)
+	if phase := in.keeper.GetElectionPhase(in.ctx); phase != types.PhaseTally {
+		t.Fatalf("an election without credentials must skip the voting phase, phase is %s", phase)
+	}
+	if _, err := in.keeper.GetCredentialPolynomial(in.ctx); err == nil {
+		t.Error("polynomial must not be built without credentials")
+	}
+	emitted := false
+	for _, e := range in.ctx.EventManager().Events() {
+		emitted = emitted || e.Type == EventTypeEmptyElectorate
+	}
+	if !emitted {
+		t.Error("closing the registration without credentials must emit an event")
+	}
+	checkGenesisRoundTrip(t, in)
+}
@@ -10,6 +10,7 @@ const (
 	BallotStoreKey          = types.BallotStoreKey
 	VoterCredentialStoreKey = types.VoterCredentialStoreKey
 	PolynomialStoreKey      = types.PolynomialStoreKey
+	ElectionStoreKey        = types.ElectionStoreKey
 	DefaultParamSpace       = types.DefaultParamSpace
 )
 
@@ -18,13 +19,29 @@ var (
 	NewQuerier             = keeper.NewQuerier
 	ModuleCdc              = types.ModuleCdc
 	RegisterCodec          = types.RegisterCodec
+	VerifyBallots          = types.VerifyBallots
+	NewVerificationReport  = types.NewVerificationReport
+	NewPolynomialAudit     = types.NewPolynomialAudit
 )
 
 type (
-	BulletinBoardKeeper      = keeper.BulletinBoardKeeper
-	Ballot                   = types.Ballot
-	MsgPutBallot             = types.MsgPutBallot
-	MsgPutVoterCredential    = types.MsgPutVoterCredential
-	QueryResVoterCredentials = types.QueryResVoterCredentials
-	Params                   = types.Params
+	BulletinBoardKeeper       = keeper.BulletinBoardKeeper
+	Ballot                    = types.Ballot
+	MsgPutBallot              = types.MsgPutBallot
+	MsgPutVoterCredential     = types.MsgPutVoterCredential
+	MsgRevokeVoterCredential  = types.MsgRevokeVoterCredential
+	MsgReplaceVoterCredential = types.MsgReplaceVoterCredential
+	MsgAddToRoll              = types.MsgAddToRoll
+	MsgRemoveFromRoll         = types.MsgRemoveFromRoll
+	RollEntry                 = types.RollEntry
+	Turnout                   = types.Turnout
+	Revocation                = types.Revocation
+	QueryResVoterCredentials  = types.QueryResVoterCredentials
+	Params                    = types.Params
+	ElectionPhase             = types.ElectionPhase
+	ElectionSchedule          = types.ElectionSchedule
+	ElectionGenerator         = types.ElectionGenerator
+	VerifyOptions             = types.VerifyOptions
+	BallotVerification        = types.BallotVerification
+	VerificationReport        = types.VerificationReport
 )
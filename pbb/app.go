@@ -88,7 +88,8 @@ func NewBulletinBoardApp(logger log.Logger, db dbm.DB,
 		distr.StoreKey,
 		VoterCredentialStoreKey,
 		BallotStoreKey,
-		PolynomialStoreKey)
+		PolynomialStoreKey,
+		ElectionStoreKey)
 
 	tkeys := sdk.NewTransientStoreKeys(staking.TStoreKey, params.TStoreKey)
 
@@ -122,6 +123,7 @@ func NewBulletinBoardApp(logger log.Logger, db dbm.DB,
 		keys[BallotStoreKey],
 		keys[VoterCredentialStoreKey],
 		keys[PolynomialStoreKey],
+		keys[ElectionStoreKey],
 		app.cdc,
 		bulletinBoardSubspace,
 	)
@@ -0,0 +1,375 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/server"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/genutil"
+	"github.com/csmuller/up-voting-system/crypto"
+	"github.com/csmuller/up-voting-system/pbb/internal/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/cli"
+)
+
+const (
+	flagRegistrationEndHeight = "registration-end-height"
+	flagRegistrationEndTime   = "registration-end-time"
+	flagVotingEndHeight       = "voting-end-height"
+	flagVotingEndTime         = "voting-end-time"
+	flagQBits                 = "q-bits"
+	flagPBits                 = "p-bits"
+	flagOBits                 = "o-bits"
+	flagRollFile              = "file"
+	flagAllowReregistration   = "allow-reregistration"
+)
+
+// GetCmdSetElectionSchedule returns a command that sets the election schedule in genesis.json.
+func GetCmdSetElectionSchedule(ctx *server.Context, cdc *codec.Codec,
+	defaultNodeHome string) *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "set-schedule",
+		Short: "Set the end of the registration and voting phase in genesis.json",
+		Long: "Set the end of the registration and voting phase in genesis.json. A phase ends at " +
+			"the given block height or block time, whichever comes first. Omitted boundaries " +
+			"are not set. The end of the registration phase is required. Without an end of " +
+			"the voting phase, the election never leaves the voting phase.",
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			regEndTime, err := parseTimeFlag(flagRegistrationEndTime)
+			if err != nil {
+				return err
+			}
+			votingEndTime, err := parseTimeFlag(flagVotingEndTime)
+			if err != nil {
+				return err
+			}
+			schedule := types.NewElectionSchedule(viper.GetInt64(flagRegistrationEndHeight),
+				regEndTime, viper.GetInt64(flagVotingEndHeight), votingEndTime)
+			if err := schedule.Validate(); err != nil {
+				return err
+			}
+			return updateGenesisState(ctx, cdc, func(genState *types.GenesisState) error {
+				genState.Params.Schedule = schedule
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().String(cli.HomeFlag, defaultNodeHome, "node's home directory")
+	cmd.Flags().Int64(flagRegistrationEndHeight, 0, "last block height of the registration phase")
+	cmd.Flags().String(flagRegistrationEndTime, "",
+		"block time (RFC3339) at which the registration phase ends")
+	cmd.Flags().Int64(flagVotingEndHeight, 0, "last block height of the voting phase")
+	cmd.Flags().String(flagVotingEndTime, "", "block time (RFC3339) at which the voting phase ends")
+	return cmd
+}
+
+// GetCmdSetGeneratorSeed returns a command that derives the commitment generators in genesis.json
+// from the given public seed.
+func GetCmdSetGeneratorSeed(ctx *server.Context, cdc *codec.Codec,
+	defaultNodeHome string) *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "set-seed [seed]",
+		Short: "Derive the commitment generators in genesis.json from the given public seed",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return updateGenesisState(ctx, cdc, func(genState *types.GenesisState) error {
+				params := genState.Params
+				genState.Params = types.NewParamsFromSeed(params.CommP.G, params.CommQ.G, args[0],
+					params.SecurityParam, params.Schedule, params.SecurityLevel)
+				genState.Params.ElectionID = params.ElectionID
+				genState.Params.Admins = params.Admins
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().String(cli.HomeFlag, defaultNodeHome, "node's home directory")
+	return cmd
+}
+
+// GetCmdSetElectionID returns a command that sets the identifier of the election in genesis.json.
+// Ballots are bound to the election ID such that they cannot be replayed in other elections.
+func GetCmdSetElectionID(ctx *server.Context, cdc *codec.Codec,
+	defaultNodeHome string) *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "set-election-id [id]",
+		Short: "Set the identifier of the election in genesis.json",
+		Long: "Set the identifier of the election in genesis.json. Ballots are bound to the " +
+			"election ID, the chain ID and the parameters and cannot be replayed in another " +
+			"election.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if strings.TrimSpace(args[0]) == "" {
+				return errors.New("the election ID cannot be empty")
+			}
+			return updateGenesisState(ctx, cdc, func(genState *types.GenesisState) error {
+				genState.Params.ElectionID = args[0]
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().String(cli.HomeFlag, defaultNodeHome, "node's home directory")
+	return cmd
+}
+
+// GetCmdAddAdmin returns a command that adds an account to the election administration in
+// genesis.json. Only accounts of the election administration can revoke voter credentials.
+func GetCmdAddAdmin(ctx *server.Context, cdc *codec.Codec,
+	defaultNodeHome string) *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "add-admin [address]",
+		Short: "Add an account to the election administration in genesis.json",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			addr, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid address '%s'\n%v", args[0], err)
+			}
+			return updateGenesisState(ctx, cdc, func(genState *types.GenesisState) error {
+				if genState.Params.IsAdmin(addr) {
+					return fmt.Errorf("%s is already an admin", addr)
+				}
+				genState.Params.Admins = append(genState.Params.Admins, addr)
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().String(cli.HomeFlag, defaultNodeHome, "node's home directory")
+	return cmd
+}
+
+// GetCmdAddToGenesisRoll returns a command that adds eligible accounts to the electoral roll in
+// genesis.json. Only accounts on the roll can register a voter credential, one each.
+func GetCmdAddToGenesisRoll(ctx *server.Context, cdc *codec.Codec,
+	defaultNodeHome string) *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "add-to-roll [address]...",
+		Short: "Add eligible accounts to the electoral roll in genesis.json",
+		Long: "Add eligible accounts to the electoral roll in genesis.json. The addresses are " +
+			"given as arguments or in a file with one address per line.",
+		RunE: func(_ *cobra.Command, args []string) error {
+			addrs, err := readAddresses(args, viper.GetString(flagRollFile))
+			if err != nil {
+				return err
+			}
+			return updateGenesisState(ctx, cdc, func(genState *types.GenesisState) error {
+				roll := genState.Roll
+				for _, addr := range addrs {
+					roll = append(roll, types.NewRollEntry(addr))
+				}
+				if err := types.ValidateRoll(roll.Addresses()); err != nil {
+					return err
+				}
+				genState.Roll = roll
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().String(cli.HomeFlag, defaultNodeHome, "node's home directory")
+	cmd.Flags().String(flagRollFile, "", "file with one address per line")
+	return cmd
+}
+
+// GetCmdSetSecurityPreset returns a command that sets the groups and the security parameter in
+// genesis.json according to the given preset.
+func GetCmdSetSecurityPreset(ctx *server.Context, cdc *codec.Codec,
+	defaultNodeHome string) *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "set-preset [name]",
+		Short: "Set the groups and the security parameter in genesis.json to the given preset",
+		Long: fmt.Sprintf("Set the groups and the security parameter in genesis.json to the given "+
+			"preset. The commitment generators are derived from the generator seed already set "+
+			"in genesis.json. Available presets are %s.",
+			strings.Join(types.SecurityPresetNames(), ", ")),
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			preset, err := types.GetSecurityPreset(args[0])
+			if err != nil {
+				return err
+			}
+			return updateGenesisState(ctx, cdc, func(genState *types.GenesisState) error {
+				params := genState.Params
+				genState.Params = preset.Params(params.GeneratorSeed, params.Schedule)
+				genState.Params.ElectionID = params.ElectionID
+				genState.Params.Admins = params.Admins
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().String(cli.HomeFlag, defaultNodeHome, "node's home directory")
+	return cmd
+}
+
+// GetCmdGenerateParameters returns a command that searches a new chain of primes q, p and o
+// defining the groups G_q and G_p and writes it to a file.
+func GetCmdGenerateParameters() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen-params [file]",
+		Short: "Generate new group parameters with the given bit lengths and write them to a file",
+		Long: "Search a new chain of primes q, p = a * q + 1 and o = k * p + 1 with the given bit " +
+			"lengths. The primes, cofactors and the Pocklington witnesses needed to re-check " +
+			"the primes are written to the given file. Use 'import-params' to inject the " +
+			"parameters into genesis.json.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chain, err := crypto.GeneratePrimeChain(viper.GetInt(flagQBits),
+				viper.GetInt(flagPBits), viper.GetInt(flagOBits))
+			if err != nil {
+				return err
+			}
+			bz, err := json.MarshalIndent(chain, "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(args[0], bz, 0644); err != nil {
+				return err
+			}
+			fmt.Println(chain.String())
+			return nil
+		},
+	}
+
+	cmd.Flags().Int(flagQBits, 160, "bit length of q, the order of G_q")
+	cmd.Flags().Int(flagPBits, 1024, "bit length of p, the order of G_p and modulus of G_q")
+	cmd.Flags().Int(flagOBits, 1034, "bit length of o, the modulus of G_p")
+	return cmd
+}
+
+// GetCmdImportParameters returns a command that re-checks the group parameters in the given file
+// and sets them in genesis.json. The commitment generators are derived anew for the new groups.
+func GetCmdImportParameters(ctx *server.Context, cdc *codec.Codec,
+	defaultNodeHome string) *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "import-params [file]",
+		Short: "Verify the group parameters in the given file and set them in genesis.json",
+		Long: "Verify the group parameters written by 'gen-params' and set them in genesis.json. " +
+			"The commitment generators are derived from the generator seed already set in " +
+			"genesis.json.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chain, err := readPrimeChain(args[0])
+			if err != nil {
+				return err
+			}
+			return updateGenesisState(ctx, cdc, func(genState *types.GenesisState) error {
+				params := genState.Params
+				gP, gQ := chain.Groups()
+				genState.Params = types.NewParamsFromSeed(&gP, &gQ, params.GeneratorSeed,
+					params.SecurityParam, params.Schedule, types.SecurityLevelCustom)
+				genState.Params.ElectionID = params.ElectionID
+				genState.Params.Admins = params.Admins
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().String(cli.HomeFlag, defaultNodeHome, "node's home directory")
+	return cmd
+}
+
+// readPrimeChain reads a prime chain from the given file and verifies it.
+func readPrimeChain(file string) (crypto.PrimeChain, error) {
+	var chain crypto.PrimeChain
+	bz, err := ioutil.ReadFile(file)
+	if err != nil {
+		return chain, err
+	}
+	if err := json.Unmarshal(bz, &chain); err != nil {
+		return chain, fmt.Errorf("failed reading the group parameters\n%v", err)
+	}
+	if err := chain.Verify(); err != nil {
+		return chain, fmt.Errorf("invalid group parameters\n%v", err)
+	}
+	return chain, nil
+}
+
+// readAddresses parses the given addresses and the addresses in the given file, one per line.
+// The file is optional. Returns an error if no address is given.
+func readAddresses(args []string, file string) ([]sdk.AccAddress, error) {
+	values := args
+	if file != "" {
+		bz, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, strings.Fields(string(bz))...)
+	}
+	if len(values) == 0 {
+		return nil, errors.New("no addresses given")
+	}
+	addrs := make([]sdk.AccAddress, len(values))
+	for i, value := range values {
+		addr, err := sdk.AccAddressFromBech32(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address '%s'\n%v", value, err)
+		}
+		addrs[i] = addr
+	}
+	return addrs, nil
+}
+
+func parseTimeFlag(flag string) (time.Time, error) {
+	value := viper.GetString(flag)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid value for --%s\n%v", flag, err)
+	}
+	return t.UTC(), nil
+}
+
+// updateGenesisState reads the bulletin board's genesis state from the genesis file of the node
+// given by the home flag, applies the update function to it and writes it back to the file.
+func updateGenesisState(ctx *server.Context, cdc *codec.Codec,
+	update func(genState *types.GenesisState) error) error {
+
+	config := ctx.Config
+	config.SetRoot(viper.GetString(cli.HomeFlag))
+	genFile := config.GenesisFile()
+	appState, genDoc, err := genutil.GenesisStateFromGenFile(cdc, genFile)
+	if err != nil {
+		return err
+	}
+
+	var genState types.GenesisState
+	if err := cdc.UnmarshalJSON(appState[types.BulletinBoardModuleName], &genState); err != nil {
+		return fmt.Errorf("failed unmarshalling the bulletin board genesis state\n%v", err)
+	}
+	if err := update(&genState); err != nil {
+		return err
+	}
+	genStateBz, err := cdc.MarshalJSON(genState)
+	if err != nil {
+		return fmt.Errorf("failed marshalling the bulletin board genesis state\n%v", err)
+	}
+	appState[types.BulletinBoardModuleName] = genStateBz
+
+	appStateJSON, err := cdc.MarshalJSON(appState)
+	if err != nil {
+		return err
+	}
+	genDoc.AppState = appStateJSON
+	return genutil.ExportGenesisFile(genDoc, genFile)
+}
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/cosmos/cosmos-sdk/client"
@@ -18,6 +20,10 @@ import (
 )
 
 const (
+	flagWorkers      = "workers"
+	flagReport       = "report"
+	flagReportFormat = "report-format"
+
 	votesFileName             = "votes.txt"
 	defaultParamsFileName     = "params.json"
 	defaultPolynomialFileName = "poly.json"
@@ -34,15 +40,23 @@ func GetQueryCmd(storeKey string, cdc *codec.Codec) *cobra.Command {
 	bulletinBoardQueryCmd.AddCommand(client.GetCommands(
 		GetCmdVerifyBallots(storeKey, cdc),
 		GetCmdVoterCredentials(storeKey, cdc),
+		GetCmdRevocations(storeKey, cdc),
+		GetCmdRoll(storeKey, cdc),
+		GetCmdTurnout(storeKey, cdc),
+		GetCmdCheckRegistration(storeKey, cdc),
+		GetCmdAuditPolynomial(storeKey, cdc),
 		GetCmdParameters(storeKey, cdc),
+		GetCmdCheckParameters(storeKey, cdc),
 		GetCmdCredentialPolynomial(storeKey, cdc),
+		GetCmdElectionPhase(storeKey, cdc),
+		GetCmdElectionGenerator(storeKey, cdc),
 	)...)
 	return bulletinBoardQueryCmd
 }
 
 // GetCmdVerifyBallots retrieves the list of all ballots and verifies each of them.
 func GetCmdVerifyBallots(queryRoute string, cdc *codec.Codec) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use: "verify",
 		Short: "Retrieve all ballots stored on the bulletin board, verify them, " +
 			"and store the valid votes in a file.",
@@ -57,20 +71,27 @@ func GetCmdVerifyBallots(queryRoute string, cdc *codec.Codec) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			if params.HHat.BigInt() == nil {
-				return errors.New("election generator has not been defined yet")
-			}
 			poly, err := QueryCredentialPolynomial(cliCtx, cdc)
 			if err != nil {
 				return err
 			}
-			commP := params.CommP
-			commQ := params.CommQ
-			// TODO: Make sure that the proof systems are immutable, i.e can be used multiple times
-			//  for different proofs.
-			ps1 := crypto.NewPolynomialEvaluationProofSystem(commP, poly)
-			ps2 := crypto.NewDoubleDiscreteLogProofSystem(commP, commQ, params.SecurityParam)
-			ps3 := crypto.NewPreimageEqualityProofSystem(params.HHat.BigInt(), commQ)
+			opts := types.VerifyOptions{
+				Workers: viper.GetInt(flagWorkers),
+				Progress: func(verified, total int) {
+					fmt.Fprintf(os.Stderr, "\rverified %d of %d ballots", verified, total)
+					if verified == total {
+						fmt.Fprintln(os.Stderr)
+					}
+				},
+			}
+			chainID, err := QueryChainID(cliCtx)
+			if err != nil {
+				return err
+			}
+			results, err := types.VerifyBallots(ballots, params, poly, chainID, opts)
+			if err != nil {
+				return err
+			}
 
 			filePath := path.Join(viper.GetString(cli.HomeFlag), votesFileName)
 			f, err := os.Create(filePath)
@@ -78,21 +99,78 @@ func GetCmdVerifyBallots(queryRoute string, cdc *codec.Codec) *cobra.Command {
 				return fmt.Errorf("couldn't create or open file '%s'\n%v", filePath, err)
 			}
 			defer f.Close()
-			for _, b := range ballots {
-				v := true
-				v = v && ps1.Verify(b.Proof1, b.C.BigInt(), b.V)
-				v = v && ps2.Verify(b.Proof2, b.C.BigInt(), b.D.BigInt(), b.V)
-				v = v && ps3.Verify(b.Proof3, b.D.BigInt(), b.UHat.BigInt(), b.V)
-				if v {
-					if _, err := f.WriteString(fmt.Sprintf("%s\n", b.V)); err != nil {
-						return fmt.Errorf("couldn't write to file '%s'\n%v", filePath, err)
-					}
+			report := types.NewVerificationReport(results)
+			for _, b := range report.Ballots {
+				if !b.Counted {
+					continue
+				}
+				if _, err := f.WriteString(fmt.Sprintf("%s\n", b.Vote)); err != nil {
+					return fmt.Errorf("couldn't write to file '%s'\n%v", filePath, err)
 				}
 			}
+			if err := writeVerificationReport(report); err != nil {
+				return err
+			}
 
+			fmt.Println(report.Summary)
+			if s := report.Summary; s.HasDiscrepancy() {
+				return discrepancyError(fmt.Sprintf("verification found %d invalid, %d malformed "+
+					"and %d duplicate ballots", s.Invalid, s.Malformed, s.Duplicates))
+			}
 			return nil
 		},
 	}
+	cmd.Flags().Int(flagWorkers, 0, "number of ballots verified in parallel, defaults to the "+
+		"number of CPUs")
+	cmd.Flags().String(flagReport, "", "file to write the verification report to, defaults to "+
+		"verification-report.<format> in the home directory")
+	cmd.Flags().String(flagReportFormat, "json", "format of the verification report, json or csv")
+	return cmd
+}
+
+// writeVerificationReport writes the report to the file and in the format given by the flags.
+func writeVerificationReport(report types.VerificationReport) error {
+	format := viper.GetString(flagReportFormat)
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("unknown report format '%s'", format)
+	}
+	filePath := viper.GetString(flagReport)
+	if filePath == "" {
+		filePath = path.Join(viper.GetString(cli.HomeFlag), "verification-report."+format)
+	}
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("couldn't create or open file '%s'\n%v", filePath, err)
+	}
+	defer f.Close()
+	if format == "csv" {
+		err = report.WriteCSV(f)
+	} else {
+		var bz []byte
+		if bz, err = json.MarshalIndent(report, "", "  "); err == nil {
+			_, err = f.Write(bz)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't write report to '%s'\n%v", filePath, err)
+	}
+	return nil
+}
+
+// discrepancyExitCode is the exit code of the verify and audit commands if they find a
+// discrepancy on the bulletin board, e.g. an invalid ballot. Other errors exit with code 1.
+const discrepancyExitCode = 2
+
+// discrepancyError is returned by the verify and audit commands if they find a discrepancy.
+type discrepancyError string
+
+func (e discrepancyError) Error() string {
+	return string(e)
+}
+
+// ExitCode implements the ExitCoder interface of Tendermint's CLI executor.
+func (e discrepancyError) ExitCode() int {
+	return discrepancyExitCode
 }
 
 func QueryBallots(cliCtx context.CLIContext, cdc *codec.Codec) ([]types.Ballot, error) {
@@ -114,19 +192,173 @@ func GetCmdVoterCredentials(queryRoute string, cdc *codec.Codec) *cobra.Command
 		Args:  cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cliCtx := context.NewCLIContext().WithCodec(cdc)
-			route := fmt.Sprintf("custom/%s/%s", queryRoute, keeper.QueryVoterCredentials)
+			out, err := QueryVoterCredentials(cliCtx, cdc)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdRevocations fetches the records of all voter credentials revoked by the election
+// administration.
+func GetCmdRevocations(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revocations",
+		Short: "Retrieve the revoked voter credentials with the height and reason of the revocation",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			route := fmt.Sprintf("custom/%s/%s", types.BulletinBoardModuleName,
+				keeper.QueryRevocations)
 			res, _, err := cliCtx.QueryWithData(route, nil)
 			if err != nil {
-				msg := sdk.AppendMsgToErr("failed querying voter credentials", err.Error())
-				return sdk.ErrInternal(msg)
+				return fmt.Errorf("failed querying revocations\n%v", err)
+			}
+			var out types.Revocations
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdRoll fetches the electoral roll or the entry of a single account in the roll.
+func GetCmdRoll(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "roll [address]",
+		Short: "Retrieve the electoral roll or the entry of the given account in the roll",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			route := fmt.Sprintf("custom/%s/%s", types.BulletinBoardModuleName, keeper.QueryRoll)
+			if len(args) == 1 {
+				route = fmt.Sprintf("%s/%s", route, args[0])
 			}
-			var out types.QueryResVoterCredentials
+			res, _, err := cliCtx.QueryWithData(route, nil)
+			if err != nil {
+				return fmt.Errorf("failed querying the electoral roll\n%v", err)
+			}
+			if len(args) == 1 {
+				var out types.RollEntry
+				cdc.MustUnmarshalJSON(res, &out)
+				return cliCtx.PrintOutput(out)
+			}
+			var out types.Roll
 			cdc.MustUnmarshalJSON(res, &out)
 			return cliCtx.PrintOutput(out)
 		},
 	}
 }
 
+// GetCmdTurnout fetches the number of eligible accounts, registered credentials and ballots.
+func GetCmdTurnout(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "turnout",
+		Short: "Retrieve the number of eligible accounts, registered credentials and ballots",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			route := fmt.Sprintf("custom/%s/%s", types.BulletinBoardModuleName,
+				keeper.QueryTurnout)
+			res, _, err := cliCtx.QueryWithData(route, nil)
+			if err != nil {
+				return fmt.Errorf("failed querying the turnout\n%v", err)
+			}
+			var out types.Turnout
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdAuditPolynomial rebuilds the credential polynomial from the registered voter credentials
+// and compares it with the credential polynomial on the bulletin board.
+func GetCmdAuditPolynomial(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use: "audit-polynomial",
+		Short: "Rebuild the credential polynomial from the registered voter credentials and " +
+			"compare it with the credential polynomial.",
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			credentials, err := QueryVoterCredentials(cliCtx, cdc)
+			if err != nil {
+				return err
+			}
+			poly, err := QueryCredentialPolynomial(cliCtx, cdc)
+			if err != nil {
+				return err
+			}
+			params, err := QueryBulletinBoardParameters(cliCtx, cdc)
+			if err != nil {
+				return err
+			}
+			audit := types.NewPolynomialAudit(credentials, poly, params)
+			if err := cliCtx.PrintOutput(audit); err != nil {
+				return err
+			}
+			if audit.HasDiscrepancy() {
+				return discrepancyError("the credential polynomial does not match the " +
+					"registered voter credentials")
+			}
+			return nil
+		},
+	}
+}
+
+func QueryVoterCredentials(cliCtx context.CLIContext, cdc *codec.Codec) (
+	types.QueryResVoterCredentials, error) {
+
+	route := fmt.Sprintf("custom/%s/%s", types.BulletinBoardModuleName,
+		keeper.QueryVoterCredentials)
+	res, _, err := cliCtx.QueryWithData(route, nil)
+	if err != nil {
+		msg := sdk.AppendMsgToErr("failed querying voter credentials", err.Error())
+		return nil, sdk.ErrInternal(msg)
+	}
+	var out types.QueryResVoterCredentials
+	cdc.MustUnmarshalJSON(res, &out)
+	return out, nil
+}
+
+// GetCmdCheckRegistration checks that a voter's public credential is registered and a root of
+// the credential polynomial, i.e. that the voter can cast a ballot.
+func GetCmdCheckRegistration(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use: "check-registration [pub cred file]",
+		Short: "Check that a public credential is registered and a root of the credential " +
+			"polynomial.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			u, err := readPublicCredential(getFileName(args, 1, defaultPubCredFileName))
+			if err != nil {
+				return err
+			}
+			phase, err := QueryElectionPhase(cliCtx, cdc)
+			if err != nil {
+				return err
+			}
+			// The credential polynomial is only built when the registration phase closes.
+			var poly *crypto.Polynomial
+			if phase.Phase != types.PhaseRegistration {
+				p, err := QueryCredentialPolynomial(cliCtx, cdc)
+				if err != nil {
+					return err
+				}
+				poly = &p
+			}
+			credentials, err := QueryVoterCredentials(cliCtx, cdc)
+			if err != nil {
+				return err
+			}
+			check := types.NewRegistrationCheck(crypto.NewInt(u), poly, credentials, phase.Phase)
+			return cliCtx.PrintOutput(check)
+		},
+	}
+}
+
 // GetCmdParameters fetches the bulletin board's set of parameters
 func GetCmdParameters(queryRoute string, cdc *codec.Codec) *cobra.Command {
 	return &cobra.Command{
@@ -158,6 +390,38 @@ func GetCmdParameters(queryRoute string, cdc *codec.Codec) *cobra.Command {
 	}
 }
 
+// GetCmdCheckParameters fetches the bulletin board's set of parameters, validates them,
+// re-derives the commitment generators from the recorded seed and checks the groups against the
+// recorded security level.
+func GetCmdCheckParameters(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "check-params",
+		Short: "Retrieve the bulletin board parameters and check that they are derived correctly",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			params, err := QueryBulletinBoardParameters(cliCtx, cdc)
+			if err != nil {
+				return err
+			}
+			if err := params.Validate(); err != nil {
+				return err
+			}
+			fmt.Println("The groups, generators and the security parameter are valid.")
+			if err := params.VerifyGenerators(); err != nil {
+				return err
+			}
+			fmt.Printf("All commitment generators are derived from seed '%s'.\n",
+				params.GeneratorSeed)
+			if err := params.VerifySecurityLevel(); err != nil {
+				return err
+			}
+			fmt.Printf("The election runs at security level '%s'.\n", params.SecurityLevel)
+			return nil
+		},
+	}
+}
+
 func getFileName(args []string, argPosition int, defaultFileName string) string {
 	if len(args) >= argPosition {
 		return args[argPosition-1]
@@ -166,6 +430,27 @@ func getFileName(args []string, argPosition int, defaultFileName string) string
 	}
 }
 
+// QueryChainID returns the chain ID that ballots are bound to. It is taken from the chain ID flag
+// or, if the flag is not set, from the status of the node. Returns an error if neither gives a
+// chain ID, because ballots bound to a wrong chain ID are rejected.
+func QueryChainID(cliCtx context.CLIContext) (string, error) {
+	if chainID := viper.GetString(client.FlagChainID); chainID != "" {
+		return chainID, nil
+	}
+	node, err := cliCtx.GetNode()
+	if err != nil {
+		return "", fmt.Errorf("no chain ID given and no node to query it from\n%v", err)
+	}
+	status, err := node.Status()
+	if err != nil {
+		return "", fmt.Errorf("no chain ID given and failed querying it from the node\n%v", err)
+	}
+	if status.NodeInfo.Network == "" {
+		return "", errors.New("no chain ID given and the node did not report one")
+	}
+	return status.NodeInfo.Network, nil
+}
+
 func QueryBulletinBoardParameters(cliCtx context.CLIContext, cdc *codec.Codec) (types.Params, error) {
 	route := fmt.Sprintf("custom/%s/%s", types.BulletinBoardModuleName, keeper.QueryParameters)
 	res, _, err := cliCtx.QueryWithData(route, nil)
@@ -220,3 +505,81 @@ func QueryCredentialPolynomial(cliCtx context.CLIContext, cdc *codec.Codec) (cry
 	cdc.MustUnmarshalJSON(res, &poly)
 	return poly, nil
 }
+
+// GetCmdElectionPhase fetches the phase the election is currently in and the phase's deadline.
+func GetCmdElectionPhase(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "phase",
+		Short: "Retrieve the current election phase and its deadline",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			phase, err := QueryElectionPhase(cliCtx, cdc)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(phase)
+		},
+	}
+}
+
+func QueryElectionPhase(cliCtx context.CLIContext, cdc *codec.Codec) (types.QueryResElectionPhase,
+	error) {
+
+	route := fmt.Sprintf("custom/%s/%s", types.BulletinBoardModuleName, keeper.QueryElectionPhase)
+	res, _, err := cliCtx.QueryWithData(route, nil)
+	if err != nil {
+		msg := sdk.AppendMsgToErr("failed querying election phase", err.Error())
+		return types.QueryResElectionPhase{}, sdk.ErrInternal(msg)
+	}
+	var phase types.QueryResElectionPhase
+	cdc.MustUnmarshalJSON(res, &phase)
+	return phase, nil
+}
+
+// GetCmdElectionGenerator fetches the record of the election generator's derivation and verifies
+// it by re-deriving the generator from the block chain and the credential polynomial.
+func GetCmdElectionGenerator(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "generator",
+		Short: "Retrieve the election generator and verify its derivation",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			route := fmt.Sprintf("custom/%s/%s", queryRoute, keeper.QueryElectionGenerator)
+			res, _, err := cliCtx.QueryWithData(route, nil)
+			if err != nil {
+				msg := sdk.AppendMsgToErr("failed querying election generator", err.Error())
+				return sdk.ErrInternal(msg)
+			}
+			var gen types.ElectionGenerator
+			cdc.MustUnmarshalJSON(res, &gen)
+
+			params, err := QueryBulletinBoardParameters(cliCtx, cdc)
+			if err != nil {
+				return err
+			}
+			poly, err := QueryCredentialPolynomial(cliCtx, cdc)
+			if err != nil {
+				return err
+			}
+			node, err := cliCtx.GetNode()
+			if err != nil {
+				return err
+			}
+			height := gen.BlockHeight
+			block, err := node.Block(&height)
+			if err != nil {
+				return fmt.Errorf("failed fetching block at height %d\n%v", height, err)
+			}
+			if !bytes.Equal(block.Block.Header.LastBlockID.Hash, gen.BlockHash) {
+				return fmt.Errorf("the recorded block hash does not match the hash of the block "+
+					"preceding block %d", height)
+			}
+			if err := gen.Verify(params, poly); err != nil {
+				return fmt.Errorf("election generator verification failed\n%v", err)
+			}
+			return cliCtx.PrintOutput(gen)
+		},
+	}
+}
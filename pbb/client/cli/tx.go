@@ -12,6 +12,7 @@ import (
 	"github.com/csmuller/up-voting-system/crypto"
 	"github.com/csmuller/up-voting-system/pbb/internal/types"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"io/ioutil"
 	"math/big"
 	"os"
@@ -36,6 +37,10 @@ func GetTxCmd(storeKey string, cdc *codec.Codec) *cobra.Command {
 	bulletinBoardTxCmd.AddCommand(client.PostCommands(
 		GetCmdGenerateAndPutVoterCredential(cdc),
 		GetCmdGenerateAndPutBallot(cdc),
+		GetCmdRevokeVoterCredential(cdc),
+		GetCmdReplaceVoterCredential(cdc),
+		GetCmdAddToRoll(cdc),
+		GetCmdRemoveFromRoll(cdc),
 	)...)
 
 	return bulletinBoardTxCmd
@@ -76,6 +81,149 @@ func GetCmdGenerateAndPutVoterCredential(cdc *codec.Codec) *cobra.Command {
 	}
 }
 
+// GetCmdRevokeVoterCredential returns a command that revokes a registered voter credential. Only
+// accounts of the election administration can revoke credentials and only in the registration
+// phase.
+func GetCmdRevokeVoterCredential(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke-credential [credential] [reason]",
+		Short: "Revoke a registered voter credential, e.g. of a voter found ineligible.",
+		Long: "Revoke a registered voter credential, e.g. of a voter found ineligible. The account " +
+			"that registered the credential cannot register another one, unless " +
+			"--allow-reregistration is given.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			u, ok := new(big.Int).SetString(strings.TrimSpace(args[0]), 10)
+			if !ok {
+				return fmt.Errorf("failed parsing voter credential '%s'", args[0])
+			}
+			msg := types.NewMsgRevokeVoterCredential(crypto.NewInt(u), args[1],
+				viper.GetBool(flagAllowReregistration), cliCtx.GetFromAddress())
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+			txBuilder := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBuilder, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().Bool(flagAllowReregistration, false,
+		"allow the account that registered the credential to register another one")
+	return cmd
+}
+
+// GetCmdReplaceVoterCredential returns a command that replaces the voter's registered credential
+// with newly generated credentials. It must be signed by the account that registered the old
+// credential and only works in the registration phase. The old credential files are kept with the
+// suffix '.old'.
+func GetCmdReplaceVoterCredential(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "replace-credential [pub key file] [priv key file] [params file]",
+		Short: "Replace the registered voter credentials with newly generated ones.",
+		Long: "Generate new voter credentials and replace the registered public credential with " +
+			"the new one. The transaction proves knowledge of the old private credentials and " +
+			"must be signed by the account that registered the old credential. The old " +
+			"credential files are renamed with the suffix '.old' and the new credentials are " +
+			"written to the given files.",
+		Args: cobra.MaximumNArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			params, err := readParameters(getFileName(args, 3, defaultParamsFileName), cdc)
+			if err != nil {
+				return err
+			}
+			pubCredFileName := getFileName(args, 1, defaultPubCredFileName)
+			privCredFileName := getFileName(args, 2, defaultPrivCredFileName)
+			oldVoter, err := getVoterFromFiles(pubCredFileName, privCredFileName)
+			if err != nil {
+				return fmt.Errorf("failed fetching voter's credentials\n%v", err)
+			}
+			ps, err := crypto.NewRepresentationProofSystem(params.CommQ)
+			if err != nil {
+				return err
+			}
+			chainID, err := QueryChainID(cliCtx)
+			if err != nil {
+				return err
+			}
+			ps.Context = types.NewBallotBinding(chainID, params).Bytes()
+			voter := crypto.GenerateNewVoter(params.CommQ)
+			replacement := crypto.NewInt(voter.U)
+			proof := ps.Generate(oldVoter, types.ReplacementProofMessage(replacement,
+				cliCtx.GetFromAddress()))
+			msg := types.NewMsgReplaceVoterCredential(crypto.NewInt(oldVoter.U), replacement,
+				proof, cliCtx.GetFromAddress())
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+			txBuilder := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			if err := utils.GenerateOrBroadcastMsgs(cliCtx, txBuilder, []sdk.Msg{msg}); err != nil {
+				return err
+			}
+			// Keep the old credentials and write the new ones to file.
+			for _, file := range []string{pubCredFileName, privCredFileName} {
+				if err := os.Rename(file, file+".old"); err != nil {
+					return fmt.Errorf("couldn't keep the old credentials in '%s.old'\n%v", file,
+						err)
+				}
+			}
+			if err := writeCredentials(pubCredFileName, voter.U); err != nil {
+				return err
+			}
+			return writeCredentials(privCredFileName, voter.A, voter.B)
+		},
+	}
+}
+
+// GetCmdAddToRoll returns a command that adds eligible accounts to the electoral roll. Only
+// accounts of the election administration can change the roll and only in the registration phase.
+func GetCmdAddToRoll(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-to-roll [address]...",
+		Short: "Add eligible accounts to the electoral roll.",
+		Long: "Add eligible accounts to the electoral roll. The addresses are given as arguments " +
+			"or in a file with one address per line.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			addrs, err := readAddresses(args, viper.GetString(flagRollFile))
+			if err != nil {
+				return err
+			}
+			msg := types.NewMsgAddToRoll(addrs, cliCtx.GetFromAddress())
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+			txBuilder := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBuilder, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(flagRollFile, "", "file with one address per line")
+	return cmd
+}
+
+// GetCmdRemoveFromRoll returns a command that removes accounts from the electoral roll. Accounts
+// that have registered a credential can only be removed after the credential has been revoked.
+func GetCmdRemoveFromRoll(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove-from-roll [address]...",
+		Short: "Remove accounts without a registered credential from the electoral roll.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			addrs, err := readAddresses(args, "")
+			if err != nil {
+				return err
+			}
+			msg := types.NewMsgRemoveFromRoll(addrs, cliCtx.GetFromAddress())
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+			txBuilder := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBuilder, []sdk.Msg{msg})
+		},
+	}
+}
+
 func readParameters(paramsFileName string, cdc *codec.Codec) (types.Params, error) {
 	paramBytes, err := readFile(paramsFileName)
 	if err != nil {
@@ -131,7 +279,7 @@ func GetCmdGenerateAndPutBallot(cdc *codec.Codec) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			if params.HHat.BigInt() == nil {
+			if !params.HasElectionGenerator() {
 				return errors.New("election generator has not been defined yet; " +
 					"you might need to query the parameters again")
 			}
@@ -154,24 +302,49 @@ func GetCmdGenerateAndPutBallot(cdc *codec.Codec) *cobra.Command {
 
 			// commitment c
 			commToURand := commP.G.ZModOrder().RandomElement()
-			commToU := commP.Commit(commToURand, voter.U)
+			commToU, err := commP.Commit(commToURand, voter.U)
+			if err != nil {
+				return fmt.Errorf("failed committing to the voter's credential\n%v", err)
+			}
 
 			// commitment d
 			commToAandBRand := commQ.G.ZModOrder().RandomElement()
-			commToAandB := commQ.Commit(commToAandBRand, voter.A, voter.B)
+			commToAandB, err := commQ.Commit(commToAandBRand, voter.A, voter.B)
+			if err != nil {
+				return fmt.Errorf("failed committing to the voter's secrets\n%v", err)
+			}
 
 			vote := args[0]
 
+			// The proofs are bound to the chain, the election and the parameters.
+			chainID, err := QueryChainID(cliCtx)
+			if err != nil {
+				return err
+			}
+			binding := types.NewBallotBinding(chainID, params).Bytes()
+
 			// 1. proof
-			ps1 := crypto.NewPolynomialEvaluationProofSystem(commP, poly)
+			ps1, err := crypto.NewPolynomialEvaluationProofSystem(commP, poly)
+			if err != nil {
+				return err
+			}
+			ps1.Context = binding
 			proof1 := ps1.Generate(voter.U, commToURand, commToU, vote)
 
 			// 2. proof
-			ps2 := crypto.NewDoubleDiscreteLogProofSystem(commP, commQ, params.SecurityParam)
+			ps2, err := crypto.NewDoubleDiscreteLogProofSystem(commP, commQ, params.SecurityParam)
+			if err != nil {
+				return err
+			}
+			ps2.Context = binding
 			proof2 := ps2.Generate(voter, commToU, commToURand, commToAandB, commToAandBRand, vote)
 
 			// 3. proof
-			ps := crypto.NewPreimageEqualityProofSystem(params.HHat.BigInt(), commQ)
+			ps, err := crypto.NewPreimageEqualityProofSystem(params.HHat.BigInt(), commQ)
+			if err != nil {
+				return err
+			}
+			ps.Context = binding
 			proof3 := ps.Generate(voter, commToAandB, commToAandBRand, uHat, vote)
 
 			// Create and send public credential transaction.
@@ -187,14 +360,10 @@ func GetCmdGenerateAndPutBallot(cdc *codec.Codec) *cobra.Command {
 }
 
 func getVoterFromFiles(pubCredFileName, privCredsFileName string) (crypto.Voter, error) {
-	pubCredString, err := readFile(pubCredFileName)
+	pubCred, err := readPublicCredential(pubCredFileName)
 	if err != nil {
 		return crypto.Voter{}, err
 	}
-	pubCred, b := new(big.Int).SetString(string(pubCredString), 10)
-	if !b {
-		return crypto.Voter{}, fmt.Errorf("failed parsing public credential.\n%v", err)
-	}
 
 	privCredsString, err := readFile(privCredsFileName)
 	if err != nil {
@@ -206,6 +375,19 @@ func getVoterFromFiles(pubCredFileName, privCredsFileName string) (crypto.Voter,
 	return crypto.NewVoter(alpha, beta, pubCred), nil
 }
 
+// readPublicCredential reads a voter's public credential from the given file.
+func readPublicCredential(pubCredFileName string) (*big.Int, error) {
+	pubCredString, err := readFile(pubCredFileName)
+	if err != nil {
+		return nil, err
+	}
+	pubCred, ok := new(big.Int).SetString(strings.TrimSpace(string(pubCredString)), 10)
+	if !ok {
+		return nil, fmt.Errorf("failed parsing public credential in file %s", pubCredFileName)
+	}
+	return pubCred, nil
+}
+
 func readFile(arg string) ([]byte, error) {
 	absPath, err := filepath.Abs(arg)
 	if err != nil {
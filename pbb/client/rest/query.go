@@ -34,6 +34,18 @@ func voterCredentialsHandler(cliCtx context.CLIContext, storeName string) http.H
 	}
 }
 
+func electionPhaseHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		route := fmt.Sprintf("custom/%s/%s", storeName, keeper.QueryElectionPhase)
+		res, _, err := cliCtx.QueryWithData(route, nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
 //func resolveNameHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
 //	return func(w http.ResponseWriter, r *http.Request) {
 //		vars := mux.Vars(r)
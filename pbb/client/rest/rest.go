@@ -19,4 +19,6 @@ func RegisterRoutes(cliCtx context.CLIContext, r *mux.Router, storeName string)
 		voterCredentialsHandler(cliCtx, storeName)).Methods("GET")
 	r.HandleFunc(fmt.Sprintf("/%s/voterCredentials", storeName),
 		putVoterCredentialsHandler(cliCtx)).Methods("PUT")
+	r.HandleFunc(fmt.Sprintf("/%s/phase", storeName),
+		electionPhaseHandler(cliCtx, storeName)).Methods("GET")
 }
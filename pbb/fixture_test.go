@@ -0,0 +1,190 @@
+package pbb
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/params"
+	"github.com/csmuller/up-voting-system/crypto"
+	"github.com/csmuller/up-voting-system/pbb/internal/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmcrypto "github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// This file contains the fixtures shared by the tests of the keeper, the handler and the genesis.
+
+const (
+	testChainID               = "test-chain"
+	testRegistrationEndHeight = 10
+	testVotingEndHeight       = 20
+)
+
+// testInput holds a bulletin board keeper on an in-memory store, a context in the registration
+// phase and the accounts used by the tests. The voters are on the electoral roll, the outsider is
+// not.
+type testInput struct {
+	ctx      sdk.Context
+	keeper   BulletinBoardKeeper
+	handler  sdk.Handler
+	params   Params
+	admin    sdk.AccAddress
+	outsider sdk.AccAddress
+	voters   []sdk.AccAddress
+}
+
+// newTestKeeper mounts the stores of the bulletin board and the params module on an in-memory
+// database and returns a keeper on them with a context at block height 1. The stores are empty.
+func newTestKeeper(t *testing.T) (sdk.Context, BulletinBoardKeeper) {
+	credentialKey := sdk.NewKVStoreKey(VoterCredentialStoreKey)
+	ballotKey := sdk.NewKVStoreKey(BallotStoreKey)
+	polyKey := sdk.NewKVStoreKey(PolynomialStoreKey)
+	electionKey := sdk.NewKVStoreKey(ElectionStoreKey)
+	paramsKey := sdk.NewKVStoreKey(params.StoreKey)
+	paramsTKey := sdk.NewTransientStoreKey(params.TStoreKey)
+
+	db := dbm.NewMemDB()
+	ms := store.NewCommitMultiStore(db)
+	for _, key := range []sdk.StoreKey{credentialKey, ballotKey, polyKey, electionKey, paramsKey} {
+		ms.MountStoreWithDB(key, sdk.StoreTypeIAVL, db)
+	}
+	ms.MountStoreWithDB(paramsTKey, sdk.StoreTypeTransient, db)
+	if err := ms.LoadLatestVersion(); err != nil {
+		t.Fatal(err)
+	}
+
+	cdc := codec.New()
+	RegisterCodec(cdc)
+	paramsKeeper := params.NewKeeper(cdc, paramsKey, paramsTKey, params.DefaultCodespace)
+	k := NewBulletinBoardKeeper(credentialKey, ballotKey, polyKey, electionKey, cdc,
+		paramsKeeper.Subspace(DefaultParamSpace))
+	header := abci.Header{ChainID: testChainID, Height: 1}
+	return sdk.NewContext(ms, header, false, log.NewNopLogger()), k
+}
+
+// newTestInput creates a keeper on empty stores and initializes it from a genesis state with the
+// given number of voters on the electoral roll.
+func newTestInput(t *testing.T, voters int) testInput {
+	ctx, k := newTestKeeper(t)
+	in := testInput{
+		ctx:      ctx,
+		keeper:   k,
+		handler:  NewHandler(k),
+		admin:    testAddress("admin"),
+		outsider: testAddress("outsider"),
+		voters:   make([]sdk.AccAddress, voters),
+	}
+	roll := make(types.Roll, voters)
+	for i := range in.voters {
+		in.voters[i] = testAddress(fmt.Sprintf("voter%d", i))
+		roll[i] = types.NewRollEntry(in.voters[i])
+	}
+	in.params = types.DefaultParams()
+	in.params.Schedule = types.NewElectionSchedule(testRegistrationEndHeight, time.Time{},
+		testVotingEndHeight, time.Time{})
+	in.params.ElectionID = "test-election"
+	in.params.Admins = []sdk.AccAddress{in.admin}
+	InitGenesis(ctx, k, types.NewGenesisState(in.params, roll))
+	return in
+}
+
+// testAddress derives the address of a test account from its name.
+func testAddress(name string) sdk.AccAddress {
+	return sdk.AccAddress(tmcrypto.AddressHash([]byte(name)))
+}
+
+// newCredential generates new voter credentials in the group of the test parameters.
+func (in testInput) newCredential() crypto.Voter {
+	return crypto.GenerateNewVoter(in.params.CommQ)
+}
+
+// register registers a new credential for the given voter through the handler and returns it.
+func (in testInput) register(t *testing.T, voter sdk.AccAddress) crypto.Voter {
+	cred := in.newCredential()
+	res := in.handler(in.ctx, types.NewMsgPutVoterCredential(crypto.NewInt(cred.U), voter))
+	if !res.IsOK() {
+		t.Fatalf("registration by %s failed: %s", voter, res.Log)
+	}
+	return cred
+}
+
+// replacementMsg creates the message replacing the given voter's credential with new credentials
+// on behalf of the given account. The proof of knowledge of the old credential is bound to the
+// test chain and parameters.
+func (in testInput) replacementMsg(t *testing.T, old, replacement crypto.Voter,
+	signer sdk.AccAddress) types.MsgReplaceVoterCredential {
+
+	ps, err := crypto.NewRepresentationProofSystem(in.params.CommQ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps.Context = types.NewBallotBinding(testChainID, in.params).Bytes()
+	u := crypto.NewInt(replacement.U)
+	proof := ps.Generate(old, types.ReplacementProofMessage(u, signer))
+	return types.NewMsgReplaceVoterCredential(crypto.NewInt(old.U), u, proof, signer)
+}
+
+// ballotMsg creates the message casting a ballot with the given vote for the given voter on
+// behalf of the given account. The ballot is generated the way the client generates it, with the
+// proofs bound to the given chain ID and parameters. The parameters must contain the election
+// generator and the polynomial must be the credential polynomial of the election.
+func ballotMsg(t *testing.T, voter crypto.Voter, vote, chainID string, params Params,
+	poly crypto.Polynomial, signer sdk.AccAddress) types.MsgPutBallot {
+
+	commP, commQ := params.CommP, params.CommQ
+	uHat := commQ.G.Exp(params.HHat.BigInt(), voter.B)
+	commToURand := commP.G.ZModOrder().RandomElement()
+	commToU, err := commP.Commit(commToURand, voter.U)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commToAandBRand := commQ.G.ZModOrder().RandomElement()
+	commToAandB, err := commQ.Commit(commToAandBRand, voter.A, voter.B)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binding := types.NewBallotBinding(chainID, params).Bytes()
+	ps1, err := crypto.NewPolynomialEvaluationProofSystem(commP, poly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps2, err := crypto.NewDoubleDiscreteLogProofSystem(commP, commQ, params.SecurityParam)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps3, err := crypto.NewPreimageEqualityProofSystem(params.HHat.BigInt(), commQ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps1.Context, ps2.Context, ps3.Context = binding, binding, binding
+	proof1 := ps1.Generate(voter.U, commToURand, commToU, vote)
+	proof2 := ps2.Generate(voter, commToU, commToURand, commToAandB, commToAandBRand, vote)
+	proof3 := ps3.Generate(voter, commToAandB, commToAandBRand, uHat, vote)
+	return types.NewMsgPutBallot(commToU, commToAandB, vote, uHat, proof1, proof2, proof3, signer)
+}
+
+// checkError fails the test unless err is an error of the bulletin board with the given code.
+func checkError(t *testing.T, err sdk.Error, code sdk.CodeType, what string) {
+	t.Helper()
+	if err == nil {
+		t.Errorf("%s must fail", what)
+	} else if err.Codespace() != types.BulletinBoardCodespace || err.Code() != code {
+		t.Errorf("%s must fail with code %d but failed with: %s", what, code, err.Error())
+	}
+}
+
+// checkResult fails the test unless res is the result of a message that failed with the given
+// code of the bulletin board.
+func checkResult(t *testing.T, res sdk.Result, code sdk.CodeType, what string) {
+	t.Helper()
+	if res.IsOK() {
+		t.Errorf("%s must fail", what)
+	} else if res.Codespace != types.BulletinBoardCodespace || res.Code != code {
+		t.Errorf("%s must fail with code %d but failed with: %s", what, code, res.Log)
+	}
+}
@@ -1,16 +1,32 @@
 package pbb
 
 import (
+	"fmt"
 	"github.com/csmuller/up-voting-system/pbb/internal/keeper"
 	"github.com/csmuller/up-voting-system/pbb/internal/types"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
+// ValidateGenesis checks the parameters of the genesis state. Besides the validity of the groups,
+// generators and the security parameter, it checks that the generators are derived from the
+// recorded seed and that the groups correspond to the recorded security level. The state of a
+// running election, i.e. the electoral roll, the registered, revoked and replaced credentials, the
+// election generator and the ballots, must be consistent with the election phase.
 func ValidateGenesis(genesisState types.GenesisState) error {
-	// TODO: Validate parameters.
-	//params := genesisState.Params
-	return nil
+	if err := genesisState.Params.Validate(); err != nil {
+		return fmt.Errorf("invalid parameters: %v", err)
+	}
+	if err := genesisState.Params.VerifyGenerators(); err != nil {
+		return err
+	}
+	if err := genesisState.Params.VerifySecurityLevel(); err != nil {
+		return err
+	}
+	if err := genesisState.Params.Schedule.Validate(); err != nil {
+		return fmt.Errorf("invalid election schedule: %v", err)
+	}
+	return genesisState.ValidateElection()
 }
 
 func DefaultGenesisState() types.GenesisState {
@@ -19,13 +35,61 @@ func DefaultGenesisState() types.GenesisState {
 	}
 }
 
-// InitGenesis - Init parameter store from genesis data
+// InitGenesis initializes the parameter store, the electoral roll and the state of a running
+// election from genesis data. If the registration phase is closed, the credential polynomial is
+// rebuilt from the registered credentials.
 func InitGenesis(ctx sdk.Context, bk keeper.BulletinBoardKeeper, data types.GenesisState) {
 	bk.SetParams(ctx, data.Params)
+	bk.SetElectionPhase(ctx, data.Phase)
+	for _, entry := range data.Roll {
+		bk.SetRollEntry(ctx, entry)
+	}
+	for _, c := range data.Credentials {
+		bk.SetVoterCredential(ctx, c.Credential, c.Registration)
+	}
+	for _, r := range data.Revocations {
+		bk.SetRevocation(ctx, r)
+	}
+	for _, r := range data.Replacements {
+		bk.SetReplacement(ctx, r)
+	}
+	if data.ElectionGenerator != nil {
+		if _, err := bk.BuildCredentialPolynomial(ctx); err != nil {
+			panic(err)
+		}
+		bk.SetElectionGenerator(ctx, *data.ElectionGenerator)
+	}
+	for _, b := range data.Ballots {
+		if err := bk.StoreBallot(ctx, b); err != nil {
+			panic(err)
+		}
+	}
 }
 
-// ExportGenesis returns a GenesisState for a given context and bulletinBoardKeeper
+// ExportGenesis returns a GenesisState for a given context and bulletinBoardKeeper. Besides the
+// parameters and the electoral roll, the state of a running election is exported. The credential
+// polynomial is not exported because it is rebuilt from the registered credentials.
 func ExportGenesis(ctx sdk.Context, bk keeper.BulletinBoardKeeper) types.GenesisState {
-	params := bk.GetParams(ctx)
-	return types.NewGenesisState(params)
+	gs := types.NewGenesisState(bk.GetParams(ctx), nil)
+	gs.Phase = bk.GetElectionPhase(ctx)
+	var err sdk.Error
+	if gs.Roll, err = bk.GetRoll(ctx); err != nil {
+		panic(err)
+	}
+	if gs.Credentials, err = bk.GetRegisteredCredentials(ctx); err != nil {
+		panic(err)
+	}
+	if gs.Revocations, err = bk.GetRevocations(ctx); err != nil {
+		panic(err)
+	}
+	if gs.Replacements, err = bk.GetReplacements(ctx); err != nil {
+		panic(err)
+	}
+	if gs.ElectionGenerator, err = bk.GetElectionGenerator(ctx); err != nil {
+		panic(err)
+	}
+	if gs.Ballots, err = bk.GetBallots(ctx); err != nil {
+		panic(err)
+	}
+	return gs
 }
@@ -0,0 +1,118 @@
+package pbb
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/csmuller/up-voting-system/crypto"
+	"github.com/csmuller/up-voting-system/pbb/internal/types"
+)
+
+// checkGenesisRoundTrip exports the state of the given input, imports it into empty stores and
+// checks that exporting it again yields the same state.
+func checkGenesisRoundTrip(t *testing.T, in testInput) types.GenesisState {
+	t.Helper()
+	exported := ExportGenesis(in.ctx, in.keeper)
+	if err := ValidateGenesis(exported); err != nil {
+		t.Fatalf("exported genesis state must be valid: %v", err)
+	}
+	ctx, k := newTestKeeper(t)
+	InitGenesis(ctx, k, exported)
+	reexported := ExportGenesis(ctx, k)
+	if !bytes.Equal(ModuleCdc.MustMarshalJSON(exported), ModuleCdc.MustMarshalJSON(reexported)) {
+		t.Error("imported genesis state must be exported unchanged")
+	}
+	return exported
+}
+
+func TestGenesisRoundTripDuringRegistration(t *testing.T) {
+	in := newTestInput(t, 3)
+	in.register(t, in.voters[0])
+	revoked := in.register(t, in.voters[1])
+	replaced := in.register(t, in.voters[2])
+	if err := in.keeper.RevokeVoterCredential(in.ctx, crypto.NewInt(revoked.U), "not eligible",
+		in.admin, false); err != nil {
+		t.Fatal(err)
+	}
+	if res := in.handler(in.ctx, in.replacementMsg(t, replaced, in.newCredential(),
+		in.voters[2])); !res.IsOK() {
+		t.Fatal(res.Log)
+	}
+
+	gs := checkGenesisRoundTrip(t, in)
+	if len(gs.Roll) != 3 || len(gs.Credentials) != 2 || len(gs.Revocations) != 1 ||
+		len(gs.Replacements) != 1 || gs.Phase != types.PhaseRegistration {
+		t.Errorf("registrations must be exported: %+v", gs)
+	}
+
+	ctx, k := newTestKeeper(t)
+	InitGenesis(ctx, k, gs)
+	if err := k.AddToRoll(ctx, in.outsider); err != nil {
+		t.Fatal(err)
+	}
+	checkError(t, k.StoreVoterCredential(ctx, crypto.NewInt(revoked.U), in.outsider),
+		types.InvalidCredential, "registration of a revoked credential after the import")
+	checkError(t, k.StoreVoterCredential(ctx, crypto.NewInt(in.newCredential().U),
+		in.voters[1]), types.AlreadyRegistered,
+		"registration by the account of a revoked credential after the import")
+}
+
+func TestGenesisRoundTripDuringVoting(t *testing.T) {
+	in := newTestInput(t, 2)
+	in.register(t, in.voters[0])
+	in.register(t, in.voters[1])
+	ctx := in.ctx.WithBlockHeight(testRegistrationEndHeight)
+	EndBlocker(ctx, in.keeper)
+	in.ctx = ctx
+
+	gs := checkGenesisRoundTrip(t, in)
+	if gs.Phase != types.PhaseVoting || gs.ElectionGenerator == nil ||
+		!gs.Params.HasElectionGenerator() {
+		t.Errorf("phase and election generator must be exported: %+v", gs)
+	}
+}
+
+func TestValidateGenesisRejectsInconsistentRegistrations(t *testing.T) {
+	in := newTestInput(t, 2)
+	in.register(t, in.voters[0])
+	gs := ExportGenesis(in.ctx, in.keeper)
+
+	unregistered := gs
+	unregistered.Credentials = nil
+	if ValidateGenesis(unregistered) == nil {
+		t.Error("roll entry with a credential that is not registered must be rejected")
+	}
+
+	outsider := gs
+	outsider.Credentials = append([]types.RegisteredCredential{}, gs.Credentials...)
+	outsider.Credentials[0].Registration.Registrant = in.outsider
+	if ValidateGenesis(outsider) == nil {
+		t.Error("credential registered by an account not on the roll must be rejected")
+	}
+
+	closed := gs
+	closed.Phase = types.PhaseVoting
+	if ValidateGenesis(closed) == nil {
+		t.Error("voting phase without an election generator must be rejected")
+	}
+}
+
+func TestValidateGenesisRequiresElectionID(t *testing.T) {
+	in := newTestInput(t, 1)
+	gs := ExportGenesis(in.ctx, in.keeper)
+	gs.Params.ElectionID = ""
+	if ValidateGenesis(gs) == nil {
+		t.Error("genesis state without an election ID must be rejected")
+	}
+}
+
+func TestValidateGenesisRequiresRegistrationEnd(t *testing.T) {
+	in := newTestInput(t, 1)
+	gs := ExportGenesis(in.ctx, in.keeper)
+	gs.Params.Schedule = types.NewElectionSchedule(0, time.Time{}, testVotingEndHeight,
+		time.Time{})
+	if ValidateGenesis(gs) == nil {
+		t.Error("schedule without an end of the registration phase must be rejected")
+	}
+}
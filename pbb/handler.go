@@ -5,17 +5,35 @@ import (
 	"fmt"
 	"github.com/csmuller/up-voting-system/crypto"
 	"github.com/csmuller/up-voting-system/pbb/internal/types"
+	"strconv"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 const (
-	EventTypeBallot          = "ballot"
-	EventTypeVoterCredential = "voterCredential"
+	EventTypeBallot               = "ballot"
+	EventTypeVoterCredential      = "voterCredential"
+	EventTypeElectionPhase        = "electionPhase"
+	EventTypeElectionGenerator    = "electionGenerator"
+	EventTypeCredentialPolynomial = "credentialPolynomial"
+	EventTypeRevocation           = "voterCredentialRevocation"
+	EventTypeReplacement          = "voterCredentialReplacement"
+	EventTypeRollAddition         = "electoralRollAddition"
+	EventTypeRollRemoval          = "electoralRollRemoval"
+	EventTypeEmptyElectorate      = "emptyElectorate"
 
 	AttributeKeyElectionCredential = "electionCredential"
 	AttributeKeyVote               = "vote"
 	AttributeKeyVoterCredential    = "voterCredential"
+	AttributeKeyPhase              = "phase"
+	AttributeKeyBlockHeight        = "blockHeight"
+	AttributeKeyElectionGenerator  = "electionGenerator"
+	AttributeKeyPolynomialHash     = "polynomialHash"
+	AttributeKeyDegree             = "degree"
+	AttributeKeyReason             = "reason"
+	AttributeKeyReplacement        = "replacement"
+	AttributeKeyAccount            = "account"
+	AttributeKeyReregistration     = "reregistration"
 )
 
 // NewHandler returns a handler for bulletin board messages
@@ -26,6 +44,14 @@ func NewHandler(keeper BulletinBoardKeeper) sdk.Handler {
 			return handleMsgPutBallot(ctx, keeper, msg)
 		case MsgPutVoterCredential:
 			return handleMsgPutVoterCredential(ctx, keeper, msg)
+		case MsgRevokeVoterCredential:
+			return handleMsgRevokeVoterCredential(ctx, keeper, msg)
+		case MsgReplaceVoterCredential:
+			return handleMsgReplaceVoterCredential(ctx, keeper, msg)
+		case MsgAddToRoll:
+			return handleMsgAddToRoll(ctx, keeper, msg)
+		case MsgRemoveFromRoll:
+			return handleMsgRemoveFromRoll(ctx, keeper, msg)
 		default:
 			errMsg := fmt.Sprintf("Unrecognized bulletin board message type: %v", msg.Type())
 			return sdk.ErrUnknownRequest(errMsg).Result()
@@ -34,23 +60,63 @@ func NewHandler(keeper BulletinBoardKeeper) sdk.Handler {
 }
 
 func handleMsgPutBallot(ctx sdk.Context, keeper BulletinBoardKeeper, msg MsgPutBallot) sdk.Result {
+	if phase := keeper.GetElectionPhase(ctx); phase != types.PhaseVoting {
+		return types.ErrBallotOutsideVoting(phase).Result()
+	}
 	// TODO: Discard the ballot if the contained vote does not adhere to a specified format.
 	if keeper.HasElectionCredential(ctx, msg.Ballot.UHat.BigInt()) {
 		return types.ErrInvalidBallot("A ballot has already been stored for this election " +
 			"credential.").Result()
 	}
 	params := keeper.GetParams(ctx)
-	if !isMembershipProofValid(msg.Ballot, params.CommP, keeper.GetCredentialPolynomial(ctx)) {
+	// Cheap structural checks run before any of the expensive proof verifications.
+	if err := msg.Ballot.ValidateElements(params); err != nil {
+		return err.Result()
+	}
+	poly, sdkErr := keeper.GetCredentialPolynomial(ctx)
+	if sdkErr != nil {
+		return sdkErr.Result()
+	}
+	ps1, err := crypto.NewPolynomialEvaluationProofSystem(params.CommP, poly)
+	if err != nil {
+		return types.ErrProofSystemSetup(err).Result()
+	}
+	ps2, err := crypto.NewDoubleDiscreteLogProofSystem(params.CommP, params.CommQ,
+		params.SecurityParam)
+	if err != nil {
+		return types.ErrProofSystemSetup(err).Result()
+	}
+	ps3, err := crypto.NewPreimageEqualityProofSystem(params.HHat.BigInt(), params.CommQ)
+	if err != nil {
+		return types.ErrProofSystemSetup(err).Result()
+	}
+	// The proofs only verify if they were generated for this chain, election and parameters.
+	binding := types.NewBallotBinding(ctx.ChainID(), params).Bytes()
+	ps1.Context, ps2.Context, ps3.Context = binding, binding, binding
+	// Verify validates the structure of each proof first and only returns an error for a
+	// malformed proof.
+	v, err := ps1.Verify(msg.Ballot.Proof1, msg.Ballot.C.BigInt(), msg.Ballot.V)
+	if err != nil {
+		return types.ErrMalformedProof("membership proof", err).Result()
+	} else if !v {
 		return types.ErrInvalidBallot("Invalid membership proof").Result()
 	}
-	if !isRepresentationProofValid(msg.Ballot, params) {
+	v, err = ps2.Verify(msg.Ballot.Proof2, msg.Ballot.C.BigInt(), msg.Ballot.D.BigInt(),
+		msg.Ballot.V)
+	if err != nil {
+		return types.ErrMalformedProof("proof of known representation", err).Result()
+	} else if !v {
 		return types.ErrInvalidBallot("Invalid proof of known representation").Result()
 	}
-	if !isPreimageProofValid(msg.Ballot, params) {
+	v, err = ps3.Verify(msg.Ballot.Proof3, msg.Ballot.D.BigInt(), msg.Ballot.UHat.BigInt(),
+		msg.Ballot.V)
+	if err != nil {
+		return types.ErrMalformedProof("pre-image proof", err).Result()
+	} else if !v {
 		return types.ErrInvalidBallot("Invalid pre-image proof").Result()
 	}
 	if err := keeper.StoreBallot(ctx, msg.Ballot); err != nil {
-		return types.ErrInvalidBallot(err.Error()).Result()
+		return err.Result()
 	}
 	ctx.EventManager().EmitEvent(sdk.NewEvent(EventTypeBallot,
 		sdk.NewAttribute(AttributeKeyElectionCredential, msg.Ballot.UHat.String()),
@@ -58,33 +124,120 @@ func handleMsgPutBallot(ctx sdk.Context, keeper BulletinBoardKeeper, msg MsgPutB
 	return sdk.Result{Code: sdk.CodeOK}
 }
 
-func isMembershipProofValid(b Ballot, commP crypto.PedersenCommitmentScheme,
-	poly crypto.Polynomial) bool {
-	ps := crypto.NewPolynomialEvaluationProofSystem(commP, poly)
-	return ps.Verify(b.Proof1, b.C.BigInt(), b.V)
-}
+func handleMsgPutVoterCredential(ctx sdk.Context, keeper BulletinBoardKeeper,
+	msg types.MsgPutVoterCredential) sdk.Result {
 
-func isRepresentationProofValid(b Ballot, params Params) bool {
-	ps := crypto.NewDoubleDiscreteLogProofSystem(params.CommP, params.CommQ, params.SecurityParam)
-	return ps.Verify(b.Proof2, b.C.BigInt(), b.D.BigInt(), b.V)
+	if phase := keeper.GetElectionPhase(ctx); phase != types.PhaseRegistration {
+		return types.ErrCredentialOutsideReg(phase).Result()
+	}
+	if !keeper.GetParams(ctx).CommQ.G.Contains(msg.Credential.BigInt()) {
+		return sdk.NewError(types.BulletinBoardCodespace, types.InvalidCredential,
+			"the voter credential is not an element of G_q").Result()
+	}
+	// Only accounts on the electoral roll can register a credential, and only one each.
+	if err := keeper.StoreVoterCredential(ctx, msg.Credential, msg.Signer); err != nil {
+		return err.Result()
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(EventTypeVoterCredential,
+		sdk.NewAttribute(AttributeKeyVoterCredential, msg.Credential.String()),
+		sdk.NewAttribute(sdk.AttributeKeySender, msg.Signer.String())))
+	return sdk.Result{Code: sdk.CodeOK}
 }
 
-func isPreimageProofValid(b Ballot, params Params) bool {
-	ps := crypto.NewPreimageEqualityProofSystem(params.HHat.BigInt(), params.CommQ)
-	return ps.Verify(b.Proof3, b.D.BigInt(), b.UHat.BigInt(), b.V)
+func handleMsgRevokeVoterCredential(ctx sdk.Context, keeper BulletinBoardKeeper,
+	msg types.MsgRevokeVoterCredential) sdk.Result {
+
+	if !keeper.GetParams(ctx).IsAdmin(msg.Signer) {
+		return types.ErrNotAdmin(msg.Signer).Result()
+	}
+	if phase := keeper.GetElectionPhase(ctx); phase != types.PhaseRegistration {
+		return types.ErrRevocationOutsideReg(phase).Result()
+	}
+	if err := keeper.RevokeVoterCredential(ctx, msg.Credential, msg.Reason, msg.Signer,
+		msg.AllowReregistration); err != nil {
+		return err.Result()
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(EventTypeRevocation,
+		sdk.NewAttribute(AttributeKeyVoterCredential, msg.Credential.String()),
+		sdk.NewAttribute(AttributeKeyReason, msg.Reason),
+		sdk.NewAttribute(AttributeKeyReregistration, strconv.FormatBool(msg.AllowReregistration)),
+		sdk.NewAttribute(sdk.AttributeKeySender, msg.Signer.String())))
+	return sdk.Result{Code: sdk.CodeOK}
 }
 
-func handleMsgPutVoterCredential(ctx sdk.Context, keeper BulletinBoardKeeper,
-	msg types.MsgPutVoterCredential) sdk.Result {
+func handleMsgReplaceVoterCredential(ctx sdk.Context, keeper BulletinBoardKeeper,
+	msg types.MsgReplaceVoterCredential) sdk.Result {
 
-	// TODO: When an identity management system is available check if the sender account belongs
-	//  to an eligible voter and store the voters identity with the public credential.
-	err := keeper.StoreVoterCredential(ctx, msg.Credential)
+	if phase := keeper.GetElectionPhase(ctx); phase != types.PhaseRegistration {
+		return types.ErrCredentialOutsideReg(phase).Result()
+	}
+	params := keeper.GetParams(ctx)
+	if !params.CommQ.G.Contains(msg.Replacement.BigInt()) {
+		return sdk.NewError(types.BulletinBoardCodespace, types.InvalidCredential,
+			"the new voter credential is not an element of G_q").Result()
+	}
+	ps, err := crypto.NewRepresentationProofSystem(params.CommQ)
 	if err != nil {
-		return sdk.NewError(types.BulletinBoardCodespace, sdk.CodeInternal, "%v", err).Result()
+		return types.ErrProofSystemSetup(err).Result()
 	}
-	ctx.EventManager().EmitEvent(sdk.NewEvent(EventTypeVoterCredential,
+	// The proof only verifies if it was generated for this chain, election and parameters, and
+	// for this replacement by this account.
+	ps.Context = types.NewBallotBinding(ctx.ChainID(), params).Bytes()
+	v, err := ps.Verify(msg.Proof, msg.Credential.BigInt(),
+		types.ReplacementProofMessage(msg.Replacement, msg.Signer))
+	if err != nil {
+		return types.ErrMalformedProof("proof of the credential's representation", err).Result()
+	} else if !v {
+		return sdk.NewError(types.BulletinBoardCodespace, types.InvalidCredential,
+			"invalid proof of the credential's representation").Result()
+	}
+	if err := keeper.ReplaceVoterCredential(ctx, msg.Credential, msg.Replacement,
+		msg.Signer); err != nil {
+		return err.Result()
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(EventTypeReplacement,
 		sdk.NewAttribute(AttributeKeyVoterCredential, msg.Credential.String()),
+		sdk.NewAttribute(AttributeKeyReplacement, msg.Replacement.String()),
 		sdk.NewAttribute(sdk.AttributeKeySender, msg.Signer.String())))
 	return sdk.Result{Code: sdk.CodeOK}
 }
+
+func handleMsgAddToRoll(ctx sdk.Context, keeper BulletinBoardKeeper,
+	msg types.MsgAddToRoll) sdk.Result {
+
+	if !keeper.GetParams(ctx).IsAdmin(msg.Signer) {
+		return types.ErrNotAdmin(msg.Signer).Result()
+	}
+	if phase := keeper.GetElectionPhase(ctx); phase != types.PhaseRegistration {
+		return types.ErrRollOutsideReg(phase).Result()
+	}
+	for _, addr := range msg.Addresses {
+		if err := keeper.AddToRoll(ctx, addr); err != nil {
+			return err.Result()
+		}
+		ctx.EventManager().EmitEvent(sdk.NewEvent(EventTypeRollAddition,
+			sdk.NewAttribute(AttributeKeyAccount, addr.String()),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Signer.String())))
+	}
+	return sdk.Result{Code: sdk.CodeOK}
+}
+
+func handleMsgRemoveFromRoll(ctx sdk.Context, keeper BulletinBoardKeeper,
+	msg types.MsgRemoveFromRoll) sdk.Result {
+
+	if !keeper.GetParams(ctx).IsAdmin(msg.Signer) {
+		return types.ErrNotAdmin(msg.Signer).Result()
+	}
+	if phase := keeper.GetElectionPhase(ctx); phase != types.PhaseRegistration {
+		return types.ErrRollOutsideReg(phase).Result()
+	}
+	for _, addr := range msg.Addresses {
+		if err := keeper.RemoveFromRoll(ctx, addr); err != nil {
+			return err.Result()
+		}
+		ctx.EventManager().EmitEvent(sdk.NewEvent(EventTypeRollRemoval,
+			sdk.NewAttribute(AttributeKeyAccount, addr.String()),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Signer.String())))
+	}
+	return sdk.Result{Code: sdk.CodeOK}
+}
@@ -0,0 +1,150 @@
+package pbb
+
+import (
+	"math/big"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/csmuller/up-voting-system/crypto"
+	"github.com/csmuller/up-voting-system/pbb/internal/types"
+)
+
+func TestHandleMsgRevokeVoterCredential(t *testing.T) {
+	in := newTestInput(t, 2)
+	cred := in.register(t, in.voters[0])
+	u := crypto.NewInt(cred.U)
+	checkResult(t, in.handler(in.ctx, types.NewMsgRevokeVoterCredential(u, "not eligible",
+		false, in.voters[0])), types.NotAdmin, "revocation by a voter")
+
+	res := in.handler(in.ctx, types.NewMsgRevokeVoterCredential(u, "not eligible", false,
+		in.admin))
+	if !res.IsOK() {
+		t.Fatalf("revocation by an admin failed: %s", res.Log)
+	}
+	checkResult(t, in.handler(in.ctx, types.NewMsgPutVoterCredential(u, in.voters[1])),
+		types.InvalidCredential, "registration of a revoked credential")
+	checkResult(t, in.handler(in.ctx, types.NewMsgPutVoterCredential(
+		crypto.NewInt(in.newCredential().U), in.voters[0])),
+		types.AlreadyRegistered, "registration by the account of a revoked credential")
+	checkResult(t, in.handler(in.ctx, in.replacementMsg(t, cred, in.newCredential(),
+		in.voters[0])), types.InvalidCredential, "replacement of a revoked credential")
+}
+
+func TestHandleMsgReplaceVoterCredential(t *testing.T) {
+	in := newTestInput(t, 2)
+	cred := in.register(t, in.voters[0])
+	replacement := in.newCredential()
+
+	// The other account knows the private credentials, e.g. because they leaked.
+	checkResult(t, in.handler(in.ctx, in.replacementMsg(t, cred, replacement, in.voters[1])),
+		types.NotRegistrant, "replacement by another account")
+	// A proof bound to another account does not verify.
+	msg := in.replacementMsg(t, cred, replacement, in.voters[1])
+	msg.Signer = in.voters[0]
+	checkResult(t, in.handler(in.ctx, msg), types.InvalidCredential,
+		"replacement with a proof bound to another account")
+
+	if res := in.handler(in.ctx, in.replacementMsg(t, cred, replacement,
+		in.voters[0])); !res.IsOK() {
+		t.Fatalf("replacement by the registrant failed: %s", res.Log)
+	}
+	checkResult(t, in.handler(in.ctx, types.NewMsgPutVoterCredential(crypto.NewInt(cred.U),
+		in.voters[1])), types.InvalidCredential, "registration of a replaced credential")
+	checkResult(t, in.handler(in.ctx, in.replacementMsg(t, replacement, cred, in.voters[0])),
+		types.InvalidCredential, "replacement with a replaced credential")
+}
+
+func TestHandleMsgPutVoterCredential(t *testing.T) {
+	in := newTestInput(t, 1)
+	in.register(t, in.voters[0])
+	u := crypto.NewInt(in.newCredential().U)
+	checkResult(t, in.handler(in.ctx, types.NewMsgPutVoterCredential(u, in.voters[0])),
+		types.AlreadyRegistered, "second registration by the same account")
+	checkResult(t, in.handler(in.ctx, types.NewMsgPutVoterCredential(u, in.outsider)),
+		types.NotEligible, "registration by an account not on the roll")
+
+	gQ := in.keeper.GetParams(in.ctx).CommQ.G
+	nonMember := big.NewInt(2)
+	for gQ.Contains(nonMember) {
+		nonMember.Add(nonMember, big.NewInt(1))
+	}
+	if err := in.keeper.AddToRoll(in.ctx, in.outsider); err != nil {
+		t.Fatal(err)
+	}
+	checkResult(t, in.handler(in.ctx, types.NewMsgPutVoterCredential(crypto.NewInt(nonMember),
+		in.outsider)), types.InvalidCredential, "registration of a non-member of G_q")
+	if entry, _ := in.keeper.GetRollEntry(in.ctx, in.outsider); entry == nil ||
+		!entry.CanRegister() {
+		t.Error("rejected registration must leave the account able to register")
+	}
+}
+
+func TestHandleMsgRemoveFromRoll(t *testing.T) {
+	in := newTestInput(t, 2)
+	cred := in.register(t, in.voters[0])
+	remove := func(signer sdk.AccAddress, addrs ...sdk.AccAddress) sdk.Result {
+		return in.handler(in.ctx, types.NewMsgRemoveFromRoll(addrs, signer))
+	}
+	checkResult(t, remove(in.voters[1], in.voters[1]), types.NotAdmin, "removal by a voter")
+	checkResult(t, remove(in.admin, in.voters[0]), types.AlreadyRegistered,
+		"removal of an account with a registered credential")
+
+	res := in.handler(in.ctx, types.NewMsgRevokeVoterCredential(crypto.NewInt(cred.U),
+		"not eligible", false, in.admin))
+	if !res.IsOK() {
+		t.Fatalf("revocation by an admin failed: %s", res.Log)
+	}
+	if res := remove(in.admin, in.voters[0], in.voters[1]); !res.IsOK() {
+		t.Fatalf("removal by an admin failed: %s", res.Log)
+	}
+	if roll, _ := in.keeper.GetRoll(in.ctx); len(roll) != 0 {
+		t.Errorf("roll must be empty: %v", roll)
+	}
+}
+
+func TestHandleMsgPutBallot(t *testing.T) {
+	in := newTestInput(t, 2)
+	voter := in.register(t, in.voters[0])
+	in.register(t, in.voters[1])
+	in.ctx = in.ctx.WithBlockHeight(testRegistrationEndHeight)
+	EndBlocker(in.ctx, in.keeper)
+	params := in.keeper.GetParams(in.ctx)
+	poly, err := in.keeper.GetCredentialPolynomial(in.ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := ballotMsg(t, voter, "yes", testChainID, params, poly, in.voters[0])
+
+	otherChain := ballotMsg(t, voter, "yes", "other-chain", params, poly, in.voters[0])
+	checkResult(t, in.handler(in.ctx, otherChain), types.InvalidBallot,
+		"ballot bound to another chain")
+	otherParams := params
+	otherParams.ElectionID = "other-election"
+	otherElection := ballotMsg(t, voter, "yes", testChainID, otherParams, poly, in.voters[0])
+	checkResult(t, in.handler(in.ctx, otherElection), types.InvalidBallot,
+		"ballot bound to another election")
+
+	b := msg.Ballot
+	identity := types.NewMsgPutBallot(b.C.BigInt(), b.D.BigInt(), b.V, big.NewInt(1), b.Proof1,
+		b.Proof2, b.Proof3, in.voters[0])
+	checkResult(t, in.handler(in.ctx, identity), types.InvalidBallotElement,
+		"ballot with the identity as election credential")
+	truncated := msg
+	truncated.Ballot.Proof1.CArr = b.Proof1.CArr[:len(b.Proof1.CArr)-1]
+	checkResult(t, in.handler(in.ctx, truncated), types.MalformedProof,
+		"ballot with a truncated membership proof")
+
+	if res := in.handler(in.ctx, msg); !res.IsOK() {
+		t.Fatalf("valid ballot was rejected: %s", res.Log)
+	}
+	if !in.keeper.HasElectionCredential(in.ctx, b.UHat.BigInt()) {
+		t.Error("valid ballot must be stored")
+	}
+	checkResult(t, in.handler(in.ctx, msg), types.InvalidBallot,
+		"second ballot with the same election credential")
+
+	tally := in.ctx.WithBlockHeight(testVotingEndHeight)
+	EndBlocker(tally, in.keeper)
+	checkResult(t, in.handler(tally, ballotMsg(t, voter, "no", testChainID, params, poly,
+		in.voters[0])), types.BallotOutsideVoting, "ballot after the voting phase")
+}
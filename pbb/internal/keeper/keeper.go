@@ -1,7 +1,6 @@
 package keeper
 
 import (
-	"encoding/binary"
 	"fmt"
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -15,37 +14,64 @@ import (
 // stored.
 var polynomialKey = make([]byte, 8)
 
+// This key is used for the current election phase in the election store.
+var phaseKey = []byte("phase")
+
+// This key is used for the record of the election generator's derivation in the election store.
+var electionGeneratorKey = []byte("electionGenerator")
+
+// This prefix is used for the records of revoked voter credentials in the election store. It is
+// followed by the credential's key in the credentials store.
+var revocationPrefix = []byte("revocation/")
+
+// This prefix is used for the records of voter credentials replaced by their voters in the
+// election store. It is followed by the replaced credential's key in the credentials store.
+var replacementPrefix = []byte("replacement/")
+
+// This prefix is used for the entries of the electoral roll in the election store. It is followed
+// by the address of the eligible account.
+var rollPrefix = []byte("roll/")
+
 // BulletinBoardKeeper maintains the link to storage and exposes getter/setter methods for the various parts of
 // the state machine
 type BulletinBoardKeeper struct {
 	credentialStoreKey sdk.StoreKey
 	ballotStoreKey     sdk.StoreKey
 	polynomialStoreKey sdk.StoreKey
+	electionStoreKey   sdk.StoreKey
 	cdc                *codec.Codec // The wire codec for binary encoding/decoding.
 	paramStore         subspace.Subspace
 }
 
 // NewBulletinBoardKeeper creates new instances of the pbb BulletinBoardKeeper
 func NewBulletinBoardKeeper(credentialStoreKey sdk.StoreKey, ballotStoreKey sdk.StoreKey,
-	polyStoreKey sdk.StoreKey, cdc *codec.Codec, paramStore subspace.Subspace) BulletinBoardKeeper {
+	polyStoreKey sdk.StoreKey, electionStoreKey sdk.StoreKey, cdc *codec.Codec,
+	paramStore subspace.Subspace) BulletinBoardKeeper {
 
 	return BulletinBoardKeeper{
 		credentialStoreKey: credentialStoreKey,
 		ballotStoreKey:     ballotStoreKey,
 		polynomialStoreKey: polyStoreKey,
+		electionStoreKey:   electionStoreKey,
 		cdc:                cdc,
 		paramStore:         paramStore.WithKeyTable(types.ParamKeyTable()),
 	}
 }
 
-func (k BulletinBoardKeeper) GetBallot(ctx sdk.Context, electionCredential big.Int) *types.Ballot {
+// GetBallot gets the ballot cast with the given election credential. Returns nil if no such ballot
+// has been stored.
+func (k BulletinBoardKeeper) GetBallot(ctx sdk.Context, electionCredential big.Int) (*types.Ballot,
+	sdk.Error) {
+
 	store := ctx.KVStore(k.ballotStoreKey)
 	if !store.Has(electionCredential.Bytes()) {
-		return nil
+		return nil, nil
+	}
+	var ballot types.Ballot
+	if err := k.cdc.UnmarshalBinaryBare(store.Get(electionCredential.Bytes()), &ballot); err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not decode ballot", err.Error()))
 	}
-	var ballot *types.Ballot
-	k.cdc.MustUnmarshalBinaryBare(store.Get(electionCredential.Bytes()), ballot)
-	return ballot
+	return &ballot, nil
 }
 
 func (k BulletinBoardKeeper) GetBallotsIterator(ctx sdk.Context) sdk.Iterator {
@@ -58,11 +84,11 @@ func (k BulletinBoardKeeper) HasElectionCredential(ctx sdk.Context, uHat *big.In
 	return store.Has(uHat.Bytes())
 }
 
-func (k BulletinBoardKeeper) StoreBallot(ctx sdk.Context, b types.Ballot) error {
+func (k BulletinBoardKeeper) StoreBallot(ctx sdk.Context, b types.Ballot) sdk.Error {
 	store := ctx.KVStore(k.ballotStoreKey)
 	if store.Has(b.UHat.BigInt().Bytes()) {
-		return fmt.Errorf("a ballot has already been stored for voter with election credential %s",
-			b.UHat.String())
+		return types.ErrInvalidBallot(fmt.Sprintf("a ballot has already been stored for voter "+
+			"with election credential %s", b.UHat.String()))
 	}
 	//TODO: Using the the credential bytes directly as key, but might be better to use something
 	// shorter. e.g. a hash of it.
@@ -81,46 +107,456 @@ func (k BulletinBoardKeeper) HasVoterCredential(ctx sdk.Context, credential big.
 	return store.Has(credentialBytes)
 }
 
-// StoreVoterCredential stores the given voter credential in the credentials KV store and updates
-// the credentials polynomial, i.e includes the credential in the polynomial. Throws an error if the
-// credential is already in the store.
-func (k BulletinBoardKeeper) StoreVoterCredential(ctx sdk.Context, credential crypto.Int) error {
+// StoreVoterCredential stores the given voter credential in the credentials KV store together
+// with the account that registered it and records the credential in the account's entry of the
+// electoral roll. The credential polynomial is only built when the registration phase closes.
+// Returns an error if the account is not on the roll or has already registered a credential, even
+// if it has been revoked, if the credential is already in the store or if it has been revoked or
+// replaced.
+func (k BulletinBoardKeeper) StoreVoterCredential(ctx sdk.Context, credential crypto.Int,
+	registrant sdk.AccAddress) sdk.Error {
+
+	entry, err := k.GetRollEntry(ctx, registrant)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return types.ErrNotEligible(registrant)
+	}
+	if !entry.CanRegister() {
+		return types.ErrAlreadyRegistered(*entry)
+	}
+	credentialBytes := k.cdc.MustMarshalBinaryBare(credential)
+	if err := k.checkNewVoterCredential(ctx, credential, credentialBytes); err != nil {
+		return err
+	}
+	k.SetVoterCredential(ctx, credential,
+		types.NewCredentialRegistration(registrant, ctx.BlockHeight()))
+	entry.Credential = credential
+	entry.BlockHeight = ctx.BlockHeight()
+	k.SetRollEntry(ctx, *entry)
+	return nil
+}
+
+// SetVoterCredential stores the given voter credential with its registration record in the
+// credentials KV store without any checks. It is used to restore the credentials from the genesis
+// state.
+func (k BulletinBoardKeeper) SetVoterCredential(ctx sdk.Context, credential crypto.Int,
+	registration types.CredentialRegistration) {
+
+	ctx.KVStore(k.credentialStoreKey).Set(k.cdc.MustMarshalBinaryBare(credential),
+		k.cdc.MustMarshalBinaryBare(registration))
+}
+
+// checkNewVoterCredential returns an error if the given credential cannot be registered because
+// it is already in the credentials KV store or because it has been revoked or replaced.
+func (k BulletinBoardKeeper) checkNewVoterCredential(ctx sdk.Context, credential crypto.Int,
+	credentialBytes []byte) sdk.Error {
+
+	if ctx.KVStore(k.credentialStoreKey).Has(credentialBytes) {
+		return sdk.NewError(types.BulletinBoardCodespace, types.InvalidCredential,
+			"the credential %s is already set", credential.String())
+	}
+	electionStore := ctx.KVStore(k.electionStoreKey)
+	if electionStore.Has(revocationKey(credentialBytes)) {
+		return sdk.NewError(types.BulletinBoardCodespace, types.InvalidCredential,
+			"the credential %s has been revoked", credential.String())
+	}
+	if electionStore.Has(replacementKey(credentialBytes)) {
+		return sdk.NewError(types.BulletinBoardCodespace, types.InvalidCredential,
+			"the credential %s has been replaced", credential.String())
+	}
+	return nil
+}
+
+// GetVoterCredential gets the registration record of the given voter credential. Returns nil if
+// the credential is not registered.
+func (k BulletinBoardKeeper) GetVoterCredential(ctx sdk.Context,
+	credential crypto.Int) (*types.CredentialRegistration, sdk.Error) {
+
 	store := ctx.KVStore(k.credentialStoreKey)
 	credentialBytes := k.cdc.MustMarshalBinaryBare(credential)
-	if store.Has(credentialBytes) {
-		return fmt.Errorf("the credential %s is already set", credential.String())
+	if !store.Has(credentialBytes) {
+		return nil, nil
 	}
-	// Save current block height with the credential.
-	b := make([]byte, 8)
-	binary.PutVarint(b, ctx.BlockHeight())
-	store.Set(credentialBytes, b)
+	var registration types.CredentialRegistration
+	if err := k.cdc.UnmarshalBinaryBare(store.Get(credentialBytes), &registration); err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not decode credential registration",
+			err.Error()))
+	}
+	return &registration, nil
+}
+
+// RevokeVoterCredential removes the given voter credential from the credentials KV store and
+// records its revocation with the given reason and admin at the current block height. The
+// registrant's entry in the electoral roll is marked as revoked, such that the registrant cannot
+// register another credential, unless re-registration is allowed, in which case the entry is
+// cleared. The credential polynomial is only built when the registration phase closes, i.e. the
+// revoked credential will not be one of its roots. Returns an error if the credential is not
+// registered.
+func (k BulletinBoardKeeper) RevokeVoterCredential(ctx sdk.Context, credential crypto.Int,
+	reason string, admin sdk.AccAddress, allowReregistration bool) sdk.Error {
 
-	// Update credential polynomial
-	k.includeCredentialInPolynomial(ctx, credential)
+	registration, err := k.GetVoterCredential(ctx, credential)
+	if err != nil {
+		return err
+	}
+	if registration == nil {
+		return sdk.NewError(types.BulletinBoardCodespace, types.InvalidCredential,
+			"the credential %s is not registered", credential.String())
+	}
+	credentialBytes := k.cdc.MustMarshalBinaryBare(credential)
+	ctx.KVStore(k.credentialStoreKey).Delete(credentialBytes)
+	k.SetRevocation(ctx, types.NewRevocation(credential, reason, admin, registration.BlockHeight,
+		ctx.BlockHeight(), allowReregistration))
+	entry, err := k.GetRollEntry(ctx, registration.Registrant)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return nil
+	}
+	if allowReregistration {
+		k.SetRollEntry(ctx, types.NewRollEntry(entry.Address))
+	} else {
+		entry.Revoked = true
+		k.SetRollEntry(ctx, *entry)
+	}
 	return nil
 }
 
-func (k BulletinBoardKeeper) includeCredentialInPolynomial(ctx sdk.Context, credential crypto.Int) {
-	store := ctx.KVStore(k.polynomialStoreKey)
-	poly := k.GetCredentialPolynomial(ctx)
-	newPoly := poly.IncludeCredential(credential.BigInt())
-	store.Set(polynomialKey, k.cdc.MustMarshalBinaryBare(newPoly))
+// ReplaceVoterCredential replaces the given registered voter credential with the replacement on
+// behalf of the given account and records the replacement at the current block height and in the
+// account's entry of the electoral roll. All checks are done before the store is written, such
+// that either both the old credential is removed and the new one is registered or the store is
+// left untouched. The credential polynomial is only built when the registration phase closes, i.e.
+// it will have the new credential as a root instead of the old one. Returns an error if the old
+// credential is not registered, if it was registered by another account, if that account is not
+// on the roll or if the replacement cannot be registered.
+func (k BulletinBoardKeeper) ReplaceVoterCredential(ctx sdk.Context, credential,
+	replacement crypto.Int, registrant sdk.AccAddress) sdk.Error {
+
+	registration, err := k.GetVoterCredential(ctx, credential)
+	if err != nil {
+		return err
+	}
+	if registration == nil {
+		return sdk.NewError(types.BulletinBoardCodespace, types.InvalidCredential,
+			"the credential %s is not registered", credential.String())
+	}
+	if !registration.Registrant.Equals(registrant) {
+		return types.ErrNotRegistrant(credential, registrant)
+	}
+	entry, err := k.GetRollEntry(ctx, registrant)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return types.ErrNotEligible(registrant)
+	}
+	replacementBytes := k.cdc.MustMarshalBinaryBare(replacement)
+	if err := k.checkNewVoterCredential(ctx, replacement, replacementBytes); err != nil {
+		return err
+	}
+	ctx.KVStore(k.credentialStoreKey).Delete(k.cdc.MustMarshalBinaryBare(credential))
+	k.SetVoterCredential(ctx, replacement,
+		types.NewCredentialRegistration(registrant, ctx.BlockHeight()))
+	k.SetReplacement(ctx, types.NewCredentialReplacement(credential, replacement, *registration,
+		ctx.BlockHeight()))
+	entry.Credential = replacement
+	entry.BlockHeight = ctx.BlockHeight()
+	k.SetRollEntry(ctx, *entry)
+	return nil
+}
+
+// SetRevocation stores the given record of a revoked voter credential.
+func (k BulletinBoardKeeper) SetRevocation(ctx sdk.Context, revocation types.Revocation) {
+	ctx.KVStore(k.electionStoreKey).Set(
+		revocationKey(k.cdc.MustMarshalBinaryBare(revocation.Credential)),
+		k.cdc.MustMarshalBinaryBare(revocation))
+}
+
+// SetReplacement stores the given record of a replaced voter credential.
+func (k BulletinBoardKeeper) SetReplacement(ctx sdk.Context,
+	replacement types.CredentialReplacement) {
+
+	ctx.KVStore(k.electionStoreKey).Set(
+		replacementKey(k.cdc.MustMarshalBinaryBare(replacement.Credential)),
+		k.cdc.MustMarshalBinaryBare(replacement))
+}
+
+// GetRevocations gets the records of all revoked voter credentials.
+func (k BulletinBoardKeeper) GetRevocations(ctx sdk.Context) (types.Revocations, sdk.Error) {
+	revocations := types.Revocations{}
+	it := sdk.KVStorePrefixIterator(ctx.KVStore(k.electionStoreKey), revocationPrefix)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		var revocation types.Revocation
+		if err := k.cdc.UnmarshalBinaryBare(it.Value(), &revocation); err != nil {
+			return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not decode revocation",
+				err.Error()))
+		}
+		revocations = append(revocations, revocation)
+	}
+	return revocations, nil
+}
+
+// GetReplacements gets the records of all voter credentials replaced by their voters.
+func (k BulletinBoardKeeper) GetReplacements(ctx sdk.Context) ([]types.CredentialReplacement,
+	sdk.Error) {
+
+	replacements := []types.CredentialReplacement{}
+	it := sdk.KVStorePrefixIterator(ctx.KVStore(k.electionStoreKey), replacementPrefix)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		var replacement types.CredentialReplacement
+		if err := k.cdc.UnmarshalBinaryBare(it.Value(), &replacement); err != nil {
+			return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not decode replacement",
+				err.Error()))
+		}
+		replacements = append(replacements, replacement)
+	}
+	return replacements, nil
+}
+
+func revocationKey(credentialBytes []byte) []byte {
+	return append(append([]byte{}, revocationPrefix...), credentialBytes...)
+}
+
+func replacementKey(credentialBytes []byte) []byte {
+	return append(append([]byte{}, replacementPrefix...), credentialBytes...)
+}
+
+// AddToRoll adds the given account to the electoral roll. Returns an error if the account is
+// already on the roll.
+func (k BulletinBoardKeeper) AddToRoll(ctx sdk.Context, addr sdk.AccAddress) sdk.Error {
+	if ctx.KVStore(k.electionStoreKey).Has(rollKey(addr)) {
+		return sdk.ErrInvalidAddress(fmt.Sprintf("the account %s is already on the electoral roll",
+			addr))
+	}
+	k.SetRollEntry(ctx, types.NewRollEntry(addr))
+	return nil
+}
+
+// RemoveFromRoll removes the given account from the electoral roll. Returns an error if the
+// account is not on the roll or if it has registered a credential, which has to be revoked first.
+// Accounts whose credential has been revoked can be removed.
+func (k BulletinBoardKeeper) RemoveFromRoll(ctx sdk.Context, addr sdk.AccAddress) sdk.Error {
+	entry, err := k.GetRollEntry(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return types.ErrNotEligible(addr)
+	}
+	if entry.HasCredential() {
+		return types.ErrAlreadyRegistered(*entry)
+	}
+	ctx.KVStore(k.electionStoreKey).Delete(rollKey(addr))
+	return nil
+}
+
+// GetRollEntry gets the entry of the given account in the electoral roll. Returns nil if the
+// account is not on the roll.
+func (k BulletinBoardKeeper) GetRollEntry(ctx sdk.Context, addr sdk.AccAddress) (*types.RollEntry,
+	sdk.Error) {
+
+	store := ctx.KVStore(k.electionStoreKey)
+	if !store.Has(rollKey(addr)) {
+		return nil, nil
+	}
+	var entry types.RollEntry
+	if err := k.cdc.UnmarshalBinaryBare(store.Get(rollKey(addr)), &entry); err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not decode roll entry",
+			err.Error()))
+	}
+	return &entry, nil
+}
+
+// GetRoll gets the entries of all accounts on the electoral roll.
+func (k BulletinBoardKeeper) GetRoll(ctx sdk.Context) (types.Roll, sdk.Error) {
+	roll := types.Roll{}
+	it := sdk.KVStorePrefixIterator(ctx.KVStore(k.electionStoreKey), rollPrefix)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		var entry types.RollEntry
+		if err := k.cdc.UnmarshalBinaryBare(it.Value(), &entry); err != nil {
+			return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not decode roll entry",
+				err.Error()))
+		}
+		roll = append(roll, entry)
+	}
+	return roll, nil
+}
+
+// SetRollEntry sets the entry of an account in the electoral roll.
+func (k BulletinBoardKeeper) SetRollEntry(ctx sdk.Context, entry types.RollEntry) {
+	ctx.KVStore(k.electionStoreKey).Set(rollKey(entry.Address), k.cdc.MustMarshalBinaryBare(entry))
+}
+
+func rollKey(addr sdk.AccAddress) []byte {
+	return append(append([]byte{}, rollPrefix...), addr.Bytes()...)
+}
+
+// GetTurnout counts the accounts on the electoral roll, the registered voter credentials and the
+// ballots cast.
+func (k BulletinBoardKeeper) GetTurnout(ctx sdk.Context) types.Turnout {
+	return types.Turnout{
+		Phase: k.GetElectionPhase(ctx),
+		Eligible: countEntries(sdk.KVStorePrefixIterator(ctx.KVStore(k.electionStoreKey),
+			rollPrefix)),
+		Registered:  countEntries(k.GetVoterCredentialsIterator(ctx)),
+		Ballots:     countEntries(k.GetBallotsIterator(ctx)),
+		BlockHeight: ctx.BlockHeight(),
+	}
+}
+
+// countEntries counts the entries of the given iterator and closes it.
+func countEntries(it sdk.Iterator) int {
+	defer it.Close()
+	n := 0
+	for ; it.Valid(); it.Next() {
+		n++
+	}
+	return n
 }
 
-func (k BulletinBoardKeeper) GetCredentialPolynomial(ctx sdk.Context) crypto.Polynomial {
+// GetVoterCredentials gets all registered voter credentials in the order of the credentials KV
+// store.
+func (k BulletinBoardKeeper) GetVoterCredentials(ctx sdk.Context) ([]crypto.Int, sdk.Error) {
+	var credentials []crypto.Int
+	it := k.GetVoterCredentialsIterator(ctx)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		var credential crypto.Int
+		if err := k.cdc.UnmarshalBinaryBare(it.Key(), &credential); err != nil {
+			return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not decode voter credential",
+				err.Error()))
+		}
+		credentials = append(credentials, credential)
+	}
+	return credentials, nil
+}
+
+// GetRegisteredCredentials gets all registered voter credentials with their registration records
+// in the order of the credentials KV store.
+func (k BulletinBoardKeeper) GetRegisteredCredentials(ctx sdk.Context) (
+	[]types.RegisteredCredential, sdk.Error) {
+
+	credentials := []types.RegisteredCredential{}
+	it := k.GetVoterCredentialsIterator(ctx)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		var credential crypto.Int
+		if err := k.cdc.UnmarshalBinaryBare(it.Key(), &credential); err != nil {
+			return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not decode voter credential",
+				err.Error()))
+		}
+		var registration types.CredentialRegistration
+		if err := k.cdc.UnmarshalBinaryBare(it.Value(), &registration); err != nil {
+			return nil, sdk.ErrInternal(sdk.AppendMsgToErr(
+				"could not decode credential registration", err.Error()))
+		}
+		credentials = append(credentials, types.NewRegisteredCredential(credential, registration))
+	}
+	return credentials, nil
+}
+
+// GetBallots gets all stored ballots in the order of the ballots KV store.
+func (k BulletinBoardKeeper) GetBallots(ctx sdk.Context) ([]types.Ballot, sdk.Error) {
+	ballots := []types.Ballot{}
+	it := k.GetBallotsIterator(ctx)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		var ballot types.Ballot
+		if err := k.cdc.UnmarshalBinaryBare(it.Value(), &ballot); err != nil {
+			return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not decode ballot", err.Error()))
+		}
+		ballots = append(ballots, ballot)
+	}
+	return ballots, nil
+}
+
+// BuildCredentialPolynomial builds the credential polynomial from all registered voter credentials
+// with a product tree and stores it. It is called once when the registration phase closes, after
+// which no credentials are accepted anymore, i.e. the polynomial is frozen. The block that closes
+// the registration phase is only committed once the polynomial is built. p - 1 of the preset groups
+// has no large power of two as a factor, so the polynomial is built with Karatsuba's method, which
+// takes minutes for 100,000 credentials and more than an hour for a million.
+func (k BulletinBoardKeeper) BuildCredentialPolynomial(ctx sdk.Context) (crypto.Polynomial,
+	sdk.Error) {
+
+	credentials, err := k.GetVoterCredentials(ctx)
+	if err != nil {
+		return crypto.Polynomial{}, err
+	}
+	roots := make([]*big.Int, len(credentials))
+	for i, c := range credentials {
+		roots[i] = c.BigInt()
+	}
+	poly := crypto.FromRoots(roots, k.GetParams(ctx).CommP.G.ZModOrder())
+	ctx.KVStore(k.polynomialStoreKey).Set(polynomialKey, k.cdc.MustMarshalBinaryBare(poly))
+	return poly, nil
+}
+
+// GetCredentialPolynomial gets the credential polynomial which has all registered voter
+// credentials as roots. Returns an error if the polynomial has not been built yet, i.e. if the
+// election is still in the registration phase.
+func (k BulletinBoardKeeper) GetCredentialPolynomial(ctx sdk.Context) (crypto.Polynomial,
+	sdk.Error) {
+
 	store := ctx.KVStore(k.polynomialStoreKey)
-	if store.Has(polynomialKey) {
-		polyBytes := store.Get(polynomialKey)
-		var poly crypto.Polynomial
-		k.cdc.MustUnmarshalBinaryBare(polyBytes, &poly)
-		return poly
-	} else {
-		// Return the initial polynomial as long as no one has registered yet.
-		params := k.GetParams(ctx)
-		gP := params.CommP.G
-		coeffs := []*big.Int{big.NewInt(1)}
-		return crypto.NewPolynomial(coeffs, gP.ZModOrder())
+	if !store.Has(polynomialKey) {
+		return crypto.Polynomial{}, types.ErrPolynomialNotBuilt()
+	}
+	var poly crypto.Polynomial
+	if err := k.cdc.UnmarshalBinaryBare(store.Get(polynomialKey), &poly); err != nil {
+		return crypto.Polynomial{}, sdk.ErrInternal(sdk.AppendMsgToErr(
+			"could not decode credential polynomial", err.Error()))
+	}
+	return poly, nil
+}
+
+// GetElectionPhase gets the phase the election is currently in. An election that has not been
+// advanced yet is in the registration phase.
+func (k BulletinBoardKeeper) GetElectionPhase(ctx sdk.Context) types.ElectionPhase {
+	store := ctx.KVStore(k.electionStoreKey)
+	bz := store.Get(phaseKey)
+	if len(bz) == 0 {
+		return types.PhaseRegistration
+	}
+	return types.ElectionPhase(bz[0])
+}
+
+// SetElectionPhase sets the phase the election is currently in.
+func (k BulletinBoardKeeper) SetElectionPhase(ctx sdk.Context, phase types.ElectionPhase) {
+	store := ctx.KVStore(k.electionStoreKey)
+	store.Set(phaseKey, []byte{byte(phase)})
+}
+
+// SetElectionGenerator stores the record of the election generator's derivation and sets the
+// derived generator as the election generator HHat in the parameters.
+func (k BulletinBoardKeeper) SetElectionGenerator(ctx sdk.Context, gen types.ElectionGenerator) {
+	store := ctx.KVStore(k.electionStoreKey)
+	store.Set(electionGeneratorKey, k.cdc.MustMarshalBinaryBare(gen))
+	k.paramStore.Set(ctx, types.HKey, gen.HHat)
+}
+
+// GetElectionGenerator gets the record of the election generator's derivation. Returns nil if
+// the generator has not been derived yet.
+func (k BulletinBoardKeeper) GetElectionGenerator(ctx sdk.Context) (*types.ElectionGenerator,
+	sdk.Error) {
+
+	store := ctx.KVStore(k.electionStoreKey)
+	if !store.Has(electionGeneratorKey) {
+		return nil, nil
+	}
+	var gen types.ElectionGenerator
+	if err := k.cdc.UnmarshalBinaryBare(store.Get(electionGeneratorKey), &gen); err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not decode election generator",
+			err.Error()))
 	}
+	return &gen, nil
 }
 
 // SetParams sets the auth module's parameters.
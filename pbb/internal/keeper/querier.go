@@ -1,7 +1,6 @@
 package keeper
 
 import (
-	"encoding/binary"
 	"fmt"
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -15,6 +14,11 @@ const (
 	QueryParameters           = "parameters"
 	QueryVoterCredentials     = "voterCredentials"
 	QueryCredentialPolynomial = "credentialPolynomial"
+	QueryElectionPhase        = "phase"
+	QueryElectionGenerator    = "electionGenerator"
+	QueryRevocations          = "revocations"
+	QueryRoll                 = "roll"
+	QueryTurnout              = "turnout"
 )
 
 // NewQuerier is the module level router for state queries
@@ -29,6 +33,16 @@ func NewQuerier(keeper BulletinBoardKeeper) sdk.Querier {
 			return queryParameters(ctx, keeper)
 		case QueryCredentialPolynomial:
 			return queryCredentialPolynomial(ctx, keeper)
+		case QueryElectionPhase:
+			return queryElectionPhase(ctx, keeper)
+		case QueryElectionGenerator:
+			return queryElectionGenerator(ctx, keeper)
+		case QueryRevocations:
+			return queryRevocations(ctx, keeper)
+		case QueryRoll:
+			return queryRoll(ctx, path[1:], keeper)
+		case QueryTurnout:
+			return queryTurnout(ctx, keeper)
 		default:
 			return nil, sdk.ErrUnknownRequest(
 				fmt.Sprintf("Unknown bulletin board query endpoint %s.", path[0]))
@@ -43,7 +57,9 @@ func queryBallots(ctx sdk.Context, keeper BulletinBoardKeeper) ([]byte, sdk.Erro
 
 	for ; it.Valid(); it.Next() {
 		var ballot types.Ballot
-		keeper.cdc.MustUnmarshalBinaryBare(it.Value(), &ballot)
+		if err := keeper.cdc.UnmarshalBinaryBare(it.Value(), &ballot); err != nil {
+			return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not decode ballot", err.Error()))
+		}
 		ballots = append(ballots, ballot)
 	}
 
@@ -61,8 +77,17 @@ func queryVoterCredentials(ctx sdk.Context, keeper BulletinBoardKeeper) ([]byte,
 
 	for ; it.Valid(); it.Next() {
 		var result types.QueryResVoterCredential
-		keeper.cdc.MustUnmarshalBinaryBare(it.Key(), &result.Credential)
-		result.BlockHeight, _ = binary.Varint(it.Value())
+		if err := keeper.cdc.UnmarshalBinaryBare(it.Key(), &result.Credential); err != nil {
+			return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not decode voter credential",
+				err.Error()))
+		}
+		var registration types.CredentialRegistration
+		if err := keeper.cdc.UnmarshalBinaryBare(it.Value(), &registration); err != nil {
+			return nil, sdk.ErrInternal(sdk.AppendMsgToErr(
+				"could not decode credential registration", err.Error()))
+		}
+		result.Registrant = registration.Registrant
+		result.BlockHeight = registration.BlockHeight
 		results = append(results, result)
 	}
 
@@ -84,7 +109,10 @@ func queryParameters(ctx sdk.Context, keeper BulletinBoardKeeper) ([]byte, sdk.E
 }
 
 func queryCredentialPolynomial(ctx sdk.Context, keeper BulletinBoardKeeper) ([]byte, sdk.Error) {
-	poly := keeper.GetCredentialPolynomial(ctx)
+	poly, sdkErr := keeper.GetCredentialPolynomial(ctx)
+	if sdkErr != nil {
+		return nil, sdkErr
+	}
 	res, err := codec.MarshalJSONIndent(types.ModuleCdc, poly)
 	if err != nil {
 		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal polynomial to JSON",
@@ -92,3 +120,92 @@ func queryCredentialPolynomial(ctx sdk.Context, keeper BulletinBoardKeeper) ([]b
 	}
 	return res, nil
 }
+
+func queryElectionPhase(ctx sdk.Context, keeper BulletinBoardKeeper) ([]byte, sdk.Error) {
+	phase := keeper.GetElectionPhase(ctx)
+	schedule := keeper.GetParams(ctx).Schedule
+	result := types.QueryResElectionPhase{
+		Phase:       phase,
+		Deadline:    schedule.Deadline(phase),
+		Schedule:    schedule,
+		BlockHeight: ctx.BlockHeight(),
+	}
+	res, err := codec.MarshalJSONIndent(types.ModuleCdc, result)
+	if err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal election phase to JSON",
+			err.Error()))
+	}
+	return res, nil
+}
+
+func queryElectionGenerator(ctx sdk.Context, keeper BulletinBoardKeeper) ([]byte, sdk.Error) {
+	gen, sdkErr := keeper.GetElectionGenerator(ctx)
+	if sdkErr != nil {
+		return nil, sdkErr
+	}
+	if gen == nil {
+		return nil, sdk.ErrUnknownRequest("the election generator is derived when the " +
+			"registration phase closes")
+	}
+	res, err := codec.MarshalJSONIndent(types.ModuleCdc, *gen)
+	if err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal election generator to "+
+			"JSON", err.Error()))
+	}
+	return res, nil
+}
+
+func queryRevocations(ctx sdk.Context, keeper BulletinBoardKeeper) ([]byte, sdk.Error) {
+	revocations, sdkErr := keeper.GetRevocations(ctx)
+	if sdkErr != nil {
+		return nil, sdkErr
+	}
+	res, err := keeper.cdc.MarshalJSONIndent(revocations, "", "  ")
+	if err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal revocations to JSON",
+			err.Error()))
+	}
+	return res, nil
+}
+
+// queryRoll returns the electoral roll or, if an address is given, the account's entry in the
+// roll.
+func queryRoll(ctx sdk.Context, path []string, keeper BulletinBoardKeeper) ([]byte, sdk.Error) {
+	var result interface{}
+	if len(path) > 0 {
+		addr, err := sdk.AccAddressFromBech32(path[0])
+		if err != nil {
+			return nil, sdk.ErrInvalidAddress(fmt.Sprintf("invalid address '%s': %v", path[0],
+				err))
+		}
+		entry, sdkErr := keeper.GetRollEntry(ctx, addr)
+		if sdkErr != nil {
+			return nil, sdkErr
+		}
+		if entry == nil {
+			return nil, types.ErrNotEligible(addr)
+		}
+		result = *entry
+	} else {
+		roll, sdkErr := keeper.GetRoll(ctx)
+		if sdkErr != nil {
+			return nil, sdkErr
+		}
+		result = roll
+	}
+	res, err := keeper.cdc.MarshalJSONIndent(result, "", "  ")
+	if err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal electoral roll to JSON",
+			err.Error()))
+	}
+	return res, nil
+}
+
+func queryTurnout(ctx sdk.Context, keeper BulletinBoardKeeper) ([]byte, sdk.Error) {
+	res, err := codec.MarshalJSONIndent(types.ModuleCdc, keeper.GetTurnout(ctx))
+	if err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal turnout to JSON",
+			err.Error()))
+	}
+	return res, nil
+}
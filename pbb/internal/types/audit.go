@@ -0,0 +1,107 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/csmuller/up-voting-system/crypto"
+)
+
+// PolynomialAudit is the result of rebuilding the credential polynomial from the list of
+// registered voter credentials and comparing it with the polynomial on the bulletin board.
+type PolynomialAudit struct {
+	Credentials   int  `json:"credentials"` // Number of registered credentials.
+	Match         bool `json:"match"`
+	DegreeOnChain int  `json:"degree_on_chain"`
+	DegreeRebuilt int  `json:"degree_rebuilt"`
+	// FirstMismatch is the degree of the first coefficient in which the polynomials differ or -1
+	// if they are equal.
+	FirstMismatch   int          `json:"first_mismatch"`
+	OnChainCoeff    crypto.Int   `json:"on_chain_coeff"`   // Coefficient of the first mismatch.
+	RebuiltCoeff    crypto.Int   `json:"rebuilt_coeff"`    // Coefficient of the first mismatch.
+	Duplicates      []crypto.Int `json:"duplicates"`       // Credentials listed more than once.
+	NonMembers      []crypto.Int `json:"non_members"`      // Credentials that are not in G_q.
+	ModulusMismatch bool         `json:"modulus_mismatch"` // The polynomial is not over Z_p.
+}
+
+// NewPolynomialAudit rebuilds the credential polynomial from the given credentials, each distinct
+// credential included once, and compares it with the given polynomial from the bulletin board.
+// It also reports credentials that are listed more than once and credentials that are not
+// elements of G_q.
+func NewPolynomialAudit(credentials QueryResVoterCredentials, poly crypto.Polynomial,
+	params Params) PolynomialAudit {
+
+	audit := PolynomialAudit{Credentials: len(credentials), FirstMismatch: -1}
+	gQ := params.CommQ.G
+	var roots []*big.Int
+	seen := make(map[string]bool)
+	for _, c := range credentials {
+		u := c.Credential.BigInt()
+		if seen[u.String()] {
+			audit.Duplicates = append(audit.Duplicates, c.Credential)
+			continue
+		}
+		seen[u.String()] = true
+		if !gQ.Contains(u) {
+			audit.NonMembers = append(audit.NonMembers, c.Credential)
+		}
+		roots = append(roots, u)
+	}
+	rebuilt := crypto.FromRoots(roots, params.CommP.G.ZModOrder())
+
+	audit.DegreeOnChain = poly.Degree()
+	audit.DegreeRebuilt = rebuilt.Degree()
+	audit.ModulusMismatch = !poly.ZModPr.Equal(rebuilt.ZModPr)
+	n := len(poly.Coeffs)
+	if len(rebuilt.Coeffs) > n {
+		n = len(rebuilt.Coeffs)
+	}
+	for i := 0; i < n; i++ {
+		a, b := coefficient(poly, i), coefficient(rebuilt, i)
+		if a.Cmp(b) != 0 {
+			audit.FirstMismatch = i
+			audit.OnChainCoeff = crypto.NewInt(a)
+			audit.RebuiltCoeff = crypto.NewInt(b)
+			break
+		}
+	}
+	audit.Match = audit.FirstMismatch < 0 && !audit.ModulusMismatch
+	return audit
+}
+
+// coefficient returns the coefficient of the given degree of the polynomial, which is 0 for
+// degrees larger than the polynomial's degree.
+func coefficient(poly crypto.Polynomial, deg int) *big.Int {
+	if deg < len(poly.Coeffs) && poly.Coeffs[deg] != nil {
+		return poly.Coeffs[deg]
+	}
+	return big.NewInt(0)
+}
+
+// HasDiscrepancy checks if the polynomials differ or if any credential is a duplicate or not an
+// element of G_q.
+func (a PolynomialAudit) HasDiscrepancy() bool {
+	return !a.Match || len(a.Duplicates) > 0 || len(a.NonMembers) > 0
+}
+
+func (a PolynomialAudit) String() string {
+	var str strings.Builder
+	str.WriteString(fmt.Sprintf("PolynomialAudit: {credentials: %d, degree on chain: %d, "+
+		"degree rebuilt: %d, match: %t}\n", a.Credentials, a.DegreeOnChain, a.DegreeRebuilt,
+		a.Match))
+	if a.ModulusMismatch {
+		str.WriteString("the polynomial on chain is not defined over Z_p\n")
+	}
+	if a.FirstMismatch >= 0 {
+		str.WriteString(fmt.Sprintf("first mismatch at degree %d: %s on chain, %s rebuilt\n",
+			a.FirstMismatch, a.OnChainCoeff, a.RebuiltCoeff))
+	}
+	for _, d := range a.Duplicates {
+		str.WriteString(fmt.Sprintf("duplicate credential: %s\n", d))
+	}
+	for _, u := range a.NonMembers {
+		str.WriteString(fmt.Sprintf("credential not in G_q: %s\n", u))
+	}
+	return str.String()
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/csmuller/up-voting-system/crypto"
 	"math/big"
 	"strings"
@@ -15,19 +16,23 @@ type Ballot struct {
 	Proof1 crypto.PolyEvalProof         `json:"p1"`
 	Proof2 crypto.DdLogProof            `json:"p2"`
 	Proof3 crypto.PreimageEqualityProof `json:"p3"`
+	// Version is the transcript version the proofs' challenges were derived with. Ballots stored
+	// before transcripts were versioned decode with version 0.
+	Version uint32 `json:"version"`
 }
 
 func NewBallot(c *big.Int, d *big.Int, v string, uHat *big.Int,
 	proof1 crypto.PolyEvalProof, proof2 crypto.DdLogProof, proof3 crypto.PreimageEqualityProof) Ballot {
 
 	return Ballot{
-		C:      crypto.NewInt(c),
-		D:      crypto.NewInt(d),
-		V:      v,
-		UHat:   crypto.NewInt(uHat),
-		Proof1: proof1,
-		Proof2: proof2,
-		Proof3: proof3,
+		C:       crypto.NewInt(c),
+		D:       crypto.NewInt(d),
+		V:       v,
+		UHat:    crypto.NewInt(uHat),
+		Proof1:  proof1,
+		Proof2:  proof2,
+		Proof3:  proof3,
+		Version: crypto.TranscriptVersion,
 	}
 }
 
@@ -37,6 +42,7 @@ func (b Ballot) String() string {
 	str.WriteString(fmt.Sprintf("\tc: %s\n", b.C.String()))
 	str.WriteString(fmt.Sprintf("\td: %s\n", b.D.String()))
 	str.WriteString(fmt.Sprintf("\tv: %s\n", b.V))
+	str.WriteString(fmt.Sprintf("\tversion: %d\n", b.Version))
 	str.WriteString(fmt.Sprintf("\tu_hat: %s\n", b.UHat))
 	str.WriteString(fmt.Sprintf("\tproof1: %s\n", b.Proof1.String()))
 	str.WriteString(fmt.Sprintf("\tproof2: %s\n", b.Proof2.String()))
@@ -44,3 +50,22 @@ func (b Ballot) String() string {
 	str.WriteString("}")
 	return str.String()
 }
+
+// ValidateElements checks that the commitment c is an element of G_p and that the commitment d
+// and the election credential uHat are elements of G_q. The election credential must not be the
+// identity, which is the credential of a voter with private credential b = 0.
+func (b Ballot) ValidateElements(params Params) sdk.Error {
+	gP, gQ := params.CommP.G, params.CommQ.G
+	if c := b.C.BigInt(); c == nil || !gP.Contains(c) {
+		return ErrInvalidBallotElement("commitment to voter public credential is not an " +
+			"element of G_p")
+	}
+	if d := b.D.BigInt(); d == nil || !gQ.Contains(d) {
+		return ErrInvalidBallotElement("commitment to voter private credentials is not an " +
+			"element of G_q")
+	}
+	if !gQ.IsGenerator(b.UHat.BigInt()) {
+		return ErrInvalidBallotElement("voter's election credential is not a generator of G_q")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"fmt"
+
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+// BallotBinding is the data the proofs of a ballot are bound to. It is absorbed into the
+// challenges of all three proofs such that a ballot accepted on one chain or in one election
+// cannot be replayed on another chain, in another election or under different parameters.
+type BallotBinding struct {
+	ChainID    string       `json:"chain_id"`
+	ElectionID string       `json:"election_id"`
+	ParamsHash cmn.HexBytes `json:"params_hash"` // Hash of the bulletin board's parameters.
+}
+
+// NewBallotBinding creates the binding for ballots on the chain with the given ID under the given
+// parameters.
+func NewBallotBinding(chainID string, params Params) BallotBinding {
+	return BallotBinding{
+		ChainID:    chainID,
+		ElectionID: params.ElectionID,
+		ParamsHash: params.Hash(),
+	}
+}
+
+// Bytes returns the binary encoding of the binding which is used as the proof systems' context.
+func (b BallotBinding) Bytes() []byte {
+	return ModuleCdc.MustMarshalBinaryBare(b)
+}
+
+func (b BallotBinding) String() string {
+	return fmt.Sprintf("BallotBinding: {chain ID: %s, election ID: %s, params hash: %s}",
+		b.ChainID, b.ElectionID, b.ParamsHash)
+}
@@ -17,6 +17,10 @@ func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(Ballot{}, "pbb/Ballot", nil)
 	cdc.RegisterConcrete(MsgPutBallot{}, "pbb/PutBallot", nil)
 	cdc.RegisterConcrete(MsgPutVoterCredential{}, "pbb/PutVoterCredential", nil)
+	cdc.RegisterConcrete(MsgRevokeVoterCredential{}, "pbb/RevokeVoterCredential", nil)
+	cdc.RegisterConcrete(MsgReplaceVoterCredential{}, "pbb/ReplaceVoterCredential", nil)
+	cdc.RegisterConcrete(MsgAddToRoll{}, "pbb/AddToRoll", nil)
+	cdc.RegisterConcrete(MsgRemoveFromRoll{}, "pbb/RemoveFromRoll", nil)
 	cdc.RegisterConcrete(crypto.Polynomial{}, "pbb/Polynomial", nil)
 	cdc.RegisterConcrete(crypto.GStarModPrime{}, "pbb/GStarModPrime", nil)
 	cdc.RegisterConcrete(crypto.ZModPrime{}, "pbb/ZModPrime", nil)
@@ -26,4 +30,5 @@ func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(crypto.DdLogProof{}, "pbb/DdLogProof", nil)
 	cdc.RegisterConcrete(crypto.PolyEvalProof{}, "pbb/PolyEvalProof", nil)
 	cdc.RegisterConcrete(crypto.PreimageEqualityProof{}, "pbb/PreimageEqualityProof", nil)
+	cdc.RegisterConcrete(crypto.RepresentationProof{}, "pbb/RepresentationProof", nil)
 }
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/csmuller/up-voting-system/crypto"
+)
+
+// CredentialRegistration is the record stored with every registered voter credential. Only the
+// account that registered a credential can replace it.
+type CredentialRegistration struct {
+	Registrant  sdk.AccAddress `json:"registrant"`   // Account that registered the credential.
+	BlockHeight int64          `json:"block_height"` // Height of the registration.
+}
+
+// NewCredentialRegistration creates a new record of a registered voter credential.
+func NewCredentialRegistration(registrant sdk.AccAddress,
+	blockHeight int64) CredentialRegistration {
+
+	return CredentialRegistration{
+		Registrant:  registrant,
+		BlockHeight: blockHeight,
+	}
+}
+
+func (r CredentialRegistration) String() string {
+	return fmt.Sprintf("registered by %s at block height %d", r.Registrant, r.BlockHeight)
+}
+
+// RegisteredCredential is a registered voter credential together with its registration record.
+type RegisteredCredential struct {
+	Credential   crypto.Int             `json:"credential"`
+	Registration CredentialRegistration `json:"registration"`
+}
+
+// NewRegisteredCredential creates a new registered voter credential.
+func NewRegisteredCredential(credential crypto.Int,
+	registration CredentialRegistration) RegisteredCredential {
+
+	return RegisteredCredential{
+		Credential:   credential,
+		Registration: registration,
+	}
+}
+
+// CredentialReplacement is the record of a voter credential replaced by the voter with a new one.
+// Like a revoked credential, a replaced credential cannot be registered again.
+type CredentialReplacement struct {
+	Credential   crypto.Int     `json:"credential"`
+	Replacement  crypto.Int     `json:"replacement"`
+	Registrant   sdk.AccAddress `json:"registrant"`    // Account that registered both credentials.
+	RegisteredAt int64          `json:"registered_at"` // Height of the replaced registration.
+	BlockHeight  int64          `json:"block_height"`  // Height of the replacement.
+}
+
+// NewCredentialReplacement creates a new record of a replaced voter credential.
+func NewCredentialReplacement(credential, replacement crypto.Int,
+	registration CredentialRegistration, blockHeight int64) CredentialReplacement {
+
+	return CredentialReplacement{
+		Credential:   credential,
+		Replacement:  replacement,
+		Registrant:   registration.Registrant,
+		RegisteredAt: registration.BlockHeight,
+		BlockHeight:  blockHeight,
+	}
+}
+
+func (r CredentialReplacement) String() string {
+	return fmt.Sprintf("%s registered at block height %d, replaced with %s at block height %d "+
+		"by %s", r.Credential, r.RegisteredAt, r.Replacement, r.BlockHeight, r.Registrant)
+}
@@ -0,0 +1,170 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+//--------------------------------------------------------------------------------------------------
+// ElectionPhase
+
+// ElectionPhase denotes the phase an election is in. An election starts in the registration phase,
+// continues with the voting phase and ends in the tally phase. Voter credentials are only accepted
+// during registration and ballots only during voting.
+type ElectionPhase byte
+
+const (
+	PhaseRegistration ElectionPhase = iota
+	PhaseVoting
+	PhaseTally
+)
+
+// String returns the name of the phase.
+func (p ElectionPhase) String() string {
+	switch p {
+	case PhaseRegistration:
+		return "registration"
+	case PhaseVoting:
+		return "voting"
+	case PhaseTally:
+		return "tally"
+	default:
+		return fmt.Sprintf("unknown (%d)", byte(p))
+	}
+}
+
+// ElectionPhaseFromString parses the given phase name.
+func ElectionPhaseFromString(s string) (ElectionPhase, error) {
+	for _, p := range []ElectionPhase{PhaseRegistration, PhaseVoting, PhaseTally} {
+		if p.String() == s {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown election phase '%s'", s)
+}
+
+// MarshalJSON serializes the phase as its name.
+func (p ElectionPhase) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON deserializes the phase from its name.
+func (p *ElectionPhase) UnmarshalJSON(bytes []byte) error {
+	var s string
+	if err := json.Unmarshal(bytes, &s); err != nil {
+		return err
+	}
+	phase, err := ElectionPhaseFromString(s)
+	if err != nil {
+		return err
+	}
+	*p = phase
+	return nil
+}
+
+//--------------------------------------------------------------------------------------------------
+// ElectionSchedule
+
+// ElectionSchedule defines when the registration and the voting phase end. Each phase can end at a
+// block height, at a block time or at whichever of the two is reached first. A zero height or a zero
+// time means that the respective boundary is not set. A phase without any boundary never ends, which
+// is only allowed for the voting phase.
+type ElectionSchedule struct {
+	RegistrationEndHeight int64     `json:"registration_end_height"`
+	RegistrationEndTime   time.Time `json:"registration_end_time"`
+	VotingEndHeight       int64     `json:"voting_end_height"`
+	VotingEndTime         time.Time `json:"voting_end_time"`
+}
+
+// NewElectionSchedule creates a new election schedule with the given phase boundaries.
+func NewElectionSchedule(registrationEndHeight int64, registrationEndTime time.Time,
+	votingEndHeight int64, votingEndTime time.Time) ElectionSchedule {
+
+	return ElectionSchedule{
+		RegistrationEndHeight: registrationEndHeight,
+		RegistrationEndTime:   registrationEndTime,
+		VotingEndHeight:       votingEndHeight,
+		VotingEndTime:         votingEndTime,
+	}
+}
+
+// RegistrationEnded checks if the registration phase is over at the given block height and time.
+// The block at the registration end height is the last block accepting voter credentials.
+func (s ElectionSchedule) RegistrationEnded(height int64, t time.Time) bool {
+	return boundaryReached(s.RegistrationEndHeight, s.RegistrationEndTime, height, t)
+}
+
+// VotingEnded checks if the voting phase is over at the given block height and time. The block
+// at the voting end height is the last block accepting ballots.
+func (s ElectionSchedule) VotingEnded(height int64, t time.Time) bool {
+	return boundaryReached(s.VotingEndHeight, s.VotingEndTime, height, t)
+}
+
+func boundaryReached(endHeight int64, endTime time.Time, height int64, t time.Time) bool {
+	if endHeight > 0 && height >= endHeight {
+		return true
+	}
+	return !endTime.IsZero() && !t.Before(endTime)
+}
+
+// Validate checks that the boundaries are not negative, that the registration phase has an end and
+// that the voting phase does not end before the registration phase. Without an end, the election
+// would never leave the registration phase.
+func (s ElectionSchedule) Validate() error {
+	if s.RegistrationEndHeight < 0 || s.VotingEndHeight < 0 {
+		return errors.New("phase end heights cannot be negative")
+	}
+	if s.RegistrationEndHeight == 0 && s.RegistrationEndTime.IsZero() {
+		return errors.New("the end of the registration phase must be set, either as a block " +
+			"height or as a block time")
+	}
+	if s.RegistrationEndHeight > 0 && s.VotingEndHeight > 0 &&
+		s.VotingEndHeight <= s.RegistrationEndHeight {
+		return fmt.Errorf("voting end height %d must be after registration end height %d",
+			s.VotingEndHeight, s.RegistrationEndHeight)
+	}
+	if !s.RegistrationEndTime.IsZero() && !s.VotingEndTime.IsZero() &&
+		!s.VotingEndTime.After(s.RegistrationEndTime) {
+		return fmt.Errorf("voting end time %s must be after registration end time %s",
+			s.VotingEndTime, s.RegistrationEndTime)
+	}
+	return nil
+}
+
+// Deadline returns a description of when the given phase ends according to this schedule.
+func (s ElectionSchedule) Deadline(p ElectionPhase) string {
+	switch p {
+	case PhaseRegistration:
+		return describeBoundary(s.RegistrationEndHeight, s.RegistrationEndTime)
+	case PhaseVoting:
+		return describeBoundary(s.VotingEndHeight, s.VotingEndTime)
+	default:
+		return "none"
+	}
+}
+
+func describeBoundary(endHeight int64, endTime time.Time) string {
+	var parts []string
+	if endHeight > 0 {
+		parts = append(parts, fmt.Sprintf("block height %d", endHeight))
+	}
+	if !endTime.IsZero() {
+		parts = append(parts, fmt.Sprintf("block time %s", endTime.Format(time.RFC3339)))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, " or ")
+}
+
+func (s ElectionSchedule) String() string {
+	var str strings.Builder
+	str.WriteString("ElectionSchedule: {\n")
+	str.WriteString(fmt.Sprintf("\tregistration ends: %s\n", s.Deadline(PhaseRegistration)))
+	str.WriteString(fmt.Sprintf("\tvoting ends: %s\n", s.Deadline(PhaseVoting)))
+	str.WriteString("}")
+	return str.String()
+}
@@ -2,15 +2,119 @@ package types
 
 import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/csmuller/up-voting-system/crypto"
 )
 
 const (
 	BulletinBoardCodespace sdk.CodespaceType = BulletinBoardModuleName
 
-	InvalidBallot     sdk.CodeType = 101
-	InvalidCredential sdk.CodeType = 201
+	InvalidBallot        sdk.CodeType = 101
+	BallotOutsideVoting  sdk.CodeType = 102
+	InvalidBallotElement sdk.CodeType = 103
+	MalformedProof       sdk.CodeType = 104
+	ProofSystemSetup     sdk.CodeType = 105
+	InvalidCredential    sdk.CodeType = 201
+	CredentialOutsideReg sdk.CodeType = 202
+	PolynomialNotBuilt   sdk.CodeType = 203
+	NotAdmin             sdk.CodeType = 204
+	RevocationOutsideReg sdk.CodeType = 205
+	NotRegistrant        sdk.CodeType = 206
+	NotEligible          sdk.CodeType = 207
+	AlreadyRegistered    sdk.CodeType = 208
+	RollOutsideReg       sdk.CodeType = 209
 )
 
 func ErrInvalidBallot(msg string) sdk.Error {
 	return sdk.NewError(BulletinBoardCodespace, InvalidBallot, msg)
 }
+
+// ErrBallotOutsideVoting is returned for ballots that are posted while the election is not in the
+// voting phase.
+func ErrBallotOutsideVoting(phase ElectionPhase) sdk.Error {
+	return sdk.NewError(BulletinBoardCodespace, BallotOutsideVoting,
+		"ballots are only accepted in the voting phase but the election is in the %s phase", phase)
+}
+
+// ErrInvalidBallotElement is returned for ballots with commitments or an election credential that
+// are not elements of the required groups.
+func ErrInvalidBallotElement(msg string) sdk.Error {
+	return sdk.NewError(BulletinBoardCodespace, InvalidBallotElement, msg)
+}
+
+// ErrMalformedProof is returned for ballots with a proof transcript that has the wrong shape or
+// contains values outside of the required groups and ranges.
+func ErrMalformedProof(proof string, err error) sdk.Error {
+	return sdk.NewError(BulletinBoardCodespace, MalformedProof, "malformed %s: %v", proof, err)
+}
+
+// ErrProofSystemSetup is returned if the proof systems for verifying a ballot cannot be set up with
+// the current parameters and credential polynomial, e.g. because no voter has registered.
+func ErrProofSystemSetup(err error) sdk.Error {
+	return sdk.NewError(BulletinBoardCodespace, ProofSystemSetup,
+		"cannot set up the proof systems: %v", err)
+}
+
+// ErrCredentialOutsideReg is returned for voter credentials that are posted while the election is
+// not in the registration phase.
+func ErrCredentialOutsideReg(phase ElectionPhase) sdk.Error {
+	return sdk.NewError(BulletinBoardCodespace, CredentialOutsideReg,
+		"voter credentials are only accepted in the registration phase but the election is in "+
+			"the %s phase", phase)
+}
+
+// ErrPolynomialNotBuilt is returned if the credential polynomial is requested before it has been
+// built at the end of the registration phase.
+func ErrPolynomialNotBuilt() sdk.Error {
+	return sdk.NewError(BulletinBoardCodespace, PolynomialNotBuilt,
+		"the credential polynomial is only built when the registration phase closes")
+}
+
+// ErrNotAdmin is returned for messages that only the election administration may post but that
+// are signed by another account.
+func ErrNotAdmin(addr sdk.AccAddress) sdk.Error {
+	return sdk.NewError(BulletinBoardCodespace, NotAdmin,
+		"the account %s does not belong to the election administration", addr)
+}
+
+// ErrRevocationOutsideReg is returned for revocations of voter credentials that are posted while
+// the election is not in the registration phase.
+func ErrRevocationOutsideReg(phase ElectionPhase) sdk.Error {
+	return sdk.NewError(BulletinBoardCodespace, RevocationOutsideReg,
+		"voter credentials can only be revoked in the registration phase but the election is in "+
+			"the %s phase", phase)
+}
+
+// ErrNotRegistrant is returned for replacements of voter credentials that are signed by another
+// account than the one that registered the credential.
+func ErrNotRegistrant(credential crypto.Int, addr sdk.AccAddress) sdk.Error {
+	return sdk.NewError(BulletinBoardCodespace, NotRegistrant,
+		"the credential %s was not registered by the account %s", credential, addr)
+}
+
+// ErrNotEligible is returned for voter credentials registered by an account that is not on the
+// electoral roll.
+func ErrNotEligible(addr sdk.AccAddress) sdk.Error {
+	return sdk.NewError(BulletinBoardCodespace, NotEligible,
+		"the account %s is not on the electoral roll", addr)
+}
+
+// ErrAlreadyRegistered is returned for voter credentials registered by an account that has
+// already registered a credential, including one that has been revoked.
+func ErrAlreadyRegistered(entry RollEntry) sdk.Error {
+	if entry.Revoked {
+		return sdk.NewError(BulletinBoardCodespace, AlreadyRegistered,
+			"the account %s has already registered the credential %s, which has been revoked",
+			entry.Address, entry.Credential)
+	}
+	return sdk.NewError(BulletinBoardCodespace, AlreadyRegistered,
+		"the account %s has already registered the credential %s", entry.Address,
+		entry.Credential)
+}
+
+// ErrRollOutsideReg is returned for changes of the electoral roll that are posted while the
+// election is not in the registration phase.
+func ErrRollOutsideReg(phase ElectionPhase) sdk.Error {
+	return sdk.NewError(BulletinBoardCodespace, RollOutsideReg,
+		"the electoral roll can only be changed in the registration phase but the election is "+
+			"in the %s phase", phase)
+}
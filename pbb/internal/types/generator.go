@@ -0,0 +1,79 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/csmuller/up-voting-system/crypto"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+// electionGeneratorDomain separates the derivation of the election generator from other uses of
+// hashing into G_q.
+const electionGeneratorDomain = "pbb/election-generator"
+
+// ElectionGenerator records the public data from which the election generator HHat was derived
+// when the registration phase was closed. HHat is derived by hashing the hash of the block
+// preceding the closing block and the hash of the final credential polynomial into G_q. Thereby
+// nobody knows the discrete logarithm of HHat with respect to the commitment generators and
+// anyone can re-derive it.
+type ElectionGenerator struct {
+	BlockHeight    int64        `json:"block_height"`    // Height of the block closing the registration.
+	BlockHash      cmn.HexBytes `json:"block_hash"`      // Hash of the block preceding the closing block.
+	PolynomialHash cmn.HexBytes `json:"polynomial_hash"` // Hash of the final credential polynomial.
+	HHat           crypto.Int   `json:"h"`
+}
+
+// NewElectionGenerator creates a new record of the derivation of the election generator.
+func NewElectionGenerator(blockHeight int64, blockHash, polyHash []byte,
+	hHat *big.Int) ElectionGenerator {
+
+	return ElectionGenerator{
+		BlockHeight:    blockHeight,
+		BlockHash:      blockHash,
+		PolynomialHash: polyHash,
+		HHat:           crypto.NewInt(hHat),
+	}
+}
+
+// DeriveElectionGenerator derives the election generator in the given group G_q from the hash of
+// the block preceding the block closing the registration and the hash of the final credential
+// polynomial.
+func DeriveElectionGenerator(gQ crypto.Group, blockHash, polyHash []byte) *big.Int {
+	return gQ.HashToElement([]byte(electionGeneratorDomain), blockHash, polyHash)
+}
+
+// Verify re-derives the election generator from the recorded block hash and the hash of the given
+// credential polynomial and checks that it is equal to the recorded generator and to the generator
+// in the given parameters.
+func (g ElectionGenerator) Verify(params Params, poly crypto.Polynomial) error {
+	if g.HHat.BigInt() == nil {
+		return errors.New("no election generator recorded")
+	}
+	if !bytes.Equal(poly.Hash(), g.PolynomialHash) {
+		return errors.New("the credential polynomial's hash does not match the recorded hash")
+	}
+	hHat := DeriveElectionGenerator(params.CommQ.G, g.BlockHash, g.PolynomialHash)
+	if hHat.Cmp(g.HHat.BigInt()) != 0 {
+		return errors.New("the re-derived election generator does not match the recorded one")
+	}
+	if !params.HasElectionGenerator() || hHat.Cmp(params.HHat.BigInt()) != 0 {
+		return errors.New("the election generator in the parameters does not match the " +
+			"re-derived one")
+	}
+	return nil
+}
+
+func (g ElectionGenerator) String() string {
+	var str strings.Builder
+	str.WriteString("ElectionGenerator: {\n")
+	str.WriteString(fmt.Sprintf("\tblock height: %d\n", g.BlockHeight))
+	str.WriteString(fmt.Sprintf("\tblock hash: %s\n", g.BlockHash))
+	str.WriteString(fmt.Sprintf("\tpolynomial hash: %s\n", g.PolynomialHash))
+	str.WriteString(fmt.Sprintf("\tHHat: %s\n", g.HHat))
+	str.WriteString("}")
+	return str.String()
+}
@@ -1,11 +1,138 @@
 package types
 
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/csmuller/up-voting-system/crypto"
+)
+
+// GenesisState is the state of the bulletin board at genesis. A new election only has parameters
+// and an electoral roll without registered credentials. The remaining fields hold the state of a
+// running election when it is exported and imported again.
 type GenesisState struct {
-	Params Params `json:"params"`
+	Params Params        `json:"params"`
+	Roll   Roll          `json:"roll"` // Accounts eligible to register a voter credential.
+	Phase  ElectionPhase `json:"phase"`
+	// Registered voter credentials together with the accounts that registered them.
+	Credentials  []RegisteredCredential  `json:"credentials"`
+	Revocations  Revocations             `json:"revocations"`
+	Replacements []CredentialReplacement `json:"replacements"`
+	// Record of the election generator's derivation, set once the registration phase is closed.
+	ElectionGenerator *ElectionGenerator `json:"election_generator"`
+	Ballots           []Ballot           `json:"ballots"`
 }
 
-func NewGenesisState(params Params) GenesisState {
+func NewGenesisState(params Params, roll Roll) GenesisState {
 	return GenesisState{
 		Params: params,
+		Roll:   roll,
+	}
+}
+
+// ValidateElection checks that the registered credentials, the electoral roll, the revocations,
+// the replacements, the election generator and the ballots are consistent with each other and
+// with the election phase. Every credential on the roll must be registered by the account of its
+// entry unless it has been revoked, and no revoked or replaced credential may still be
+// registered. Once the registration phase is closed, the election generator must be derived from
+// the polynomial of the registered credentials, unless no credential was registered and the
+// election went straight to the tally phase. The proofs of the ballots are not verified.
+func (gs GenesisState) ValidateElection() error {
+	if err := ValidateRoll(gs.Roll.Addresses()); err != nil {
+		return fmt.Errorf("invalid electoral roll: %v", err)
+	}
+	if err := gs.validateCredentials(); err != nil {
+		return err
+	}
+	if gs.Phase == PhaseRegistration {
+		if gs.ElectionGenerator != nil || gs.Params.HasElectionGenerator() {
+			return errors.New("the election generator cannot be derived before the " +
+				"registration phase is closed")
+		}
+		if len(gs.Ballots) > 0 {
+			return errors.New("ballots cannot be cast before the registration phase is closed")
+		}
+		return nil
+	}
+	if gs.ElectionGenerator == nil {
+		// An election without registered credentials skips the voting phase.
+		if gs.Phase == PhaseTally && len(gs.Credentials) == 0 && len(gs.Ballots) == 0 &&
+			!gs.Params.HasElectionGenerator() {
+			return nil
+		}
+		return fmt.Errorf("the election generator is missing in the %s phase", gs.Phase)
+	}
+	roots := make([]*big.Int, len(gs.Credentials))
+	for i, c := range gs.Credentials {
+		roots[i] = c.Credential.BigInt()
+	}
+	poly := crypto.FromRoots(roots, gs.Params.CommP.G.ZModOrder())
+	if err := gs.ElectionGenerator.Verify(gs.Params, poly); err != nil {
+		return fmt.Errorf("invalid election generator: %v", err)
+	}
+	uHats := make(map[string]bool, len(gs.Ballots))
+	for _, b := range gs.Ballots {
+		if uHats[b.UHat.String()] {
+			return fmt.Errorf("more than one ballot cast with the election credential %s", b.UHat)
+		}
+		uHats[b.UHat.String()] = true
+	}
+	return nil
+}
+
+// validateCredentials checks the registered, revoked and replaced credentials against each other
+// and against the electoral roll.
+func (gs GenesisState) validateCredentials() error {
+	entries := make(map[string]RollEntry, len(gs.Roll))
+	for _, e := range gs.Roll {
+		entries[string(e.Address)] = e
+	}
+	gQ := gs.Params.CommQ.G
+	registered := make(map[string]bool, len(gs.Credentials))
+	for _, c := range gs.Credentials {
+		if !gQ.Contains(c.Credential.BigInt()) {
+			return fmt.Errorf("the credential %s is not an element of G_q", c.Credential)
+		}
+		if registered[c.Credential.String()] {
+			return fmt.Errorf("the credential %s is registered more than once", c.Credential)
+		}
+		registered[c.Credential.String()] = true
+		e, ok := entries[string(c.Registration.Registrant)]
+		if !ok {
+			return fmt.Errorf("the credential %s is registered by %s, which is not on the "+
+				"electoral roll", c.Credential, c.Registration.Registrant)
+		}
+		if !e.HasCredential() || e.Credential.BigInt().Cmp(c.Credential.BigInt()) != 0 ||
+			e.BlockHeight != c.Registration.BlockHeight {
+			return fmt.Errorf("the credential %s does not match the roll entry: %s",
+				c.Credential, e)
+		}
+	}
+	for _, e := range gs.Roll {
+		if e.HasCredential() && !registered[e.Credential.String()] {
+			return fmt.Errorf("the credential of the roll entry is not registered: %s", e)
+		}
+	}
+	removed := make(map[string]bool, len(gs.Revocations)+len(gs.Replacements))
+	for _, r := range gs.Revocations {
+		if registered[r.Credential.String()] || removed[r.Credential.String()] {
+			return fmt.Errorf("the revoked credential %s is still registered or removed more "+
+				"than once", r.Credential)
+		}
+		removed[r.Credential.String()] = true
+	}
+	for _, r := range gs.Replacements {
+		if registered[r.Credential.String()] || removed[r.Credential.String()] {
+			return fmt.Errorf("the replaced credential %s is still registered or removed more "+
+				"than once", r.Credential)
+		}
+		removed[r.Credential.String()] = true
+	}
+	for _, e := range gs.Roll {
+		if e.Revoked && !removed[e.Credential.String()] {
+			return fmt.Errorf("the credential of the roll entry has not been revoked: %s", e)
+		}
 	}
+	return nil
 }
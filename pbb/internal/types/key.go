@@ -5,4 +5,5 @@ const (
 	VoterCredentialStoreKey = "pbb.voterCredentials"
 	BallotStoreKey          = "pbb.ballots"
 	PolynomialStoreKey      = "pbb.polynomial"
+	ElectionStoreKey        = "pbb.election"
 )
@@ -4,6 +4,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/csmuller/up-voting-system/crypto"
 	"math/big"
+	"strings"
 )
 
 //--------------------------------------------------------------------------------------------------
@@ -41,6 +42,9 @@ func (msg MsgPutVoterCredential) ValidateBasic() sdk.Error {
 		return sdk.NewError(BulletinBoardCodespace, InvalidCredential,
 			"voter credential value cannot be zero or negative")
 	}
+	if msg.Signer.Empty() {
+		return sdk.ErrInvalidAddress("missing signer address")
+	}
 	return nil
 }
 
@@ -54,6 +58,257 @@ func (msg MsgPutVoterCredential) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{msg.Signer}
 }
 
+//--------------------------------------------------------------------------------------------------
+// MsgRevokeVoterCredential
+
+// MaxRevocationReasonLength is the maximal length of the reason given for a revocation.
+const MaxRevocationReasonLength = 256
+
+var _ sdk.Msg = MsgRevokeVoterCredential{}
+
+// MsgRevokeVoterCredential defines the message for revoking a registered voter credential, e.g.
+// because the voter is not eligible. Only the election administration may revoke credentials. The
+// account that registered the credential can only register another one if AllowReregistration is
+// set, e.g. if the credential was registered by mistake.
+type MsgRevokeVoterCredential struct {
+	Credential          crypto.Int     `json:"u"`
+	Reason              string         `json:"reason"`
+	AllowReregistration bool           `json:"allow_reregistration"`
+	Signer              sdk.AccAddress `json:"signer"`
+}
+
+// NewMsgRevokeVoterCredential creates a new instance of the MsgRevokeVoterCredential message.
+func NewMsgRevokeVoterCredential(credential crypto.Int, reason string, allowReregistration bool,
+	signer sdk.AccAddress) MsgRevokeVoterCredential {
+
+	return MsgRevokeVoterCredential{
+		Credential:          credential,
+		Reason:              reason,
+		AllowReregistration: allowReregistration,
+		Signer:              signer,
+	}
+}
+
+// Route returns the name of the module.
+func (msg MsgRevokeVoterCredential) Route() string {
+	return BulletinBoardModuleName
+}
+
+// Type returns the action of the message.
+func (msg MsgRevokeVoterCredential) Type() string {
+	return "revoke_voter_credential"
+}
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgRevokeVoterCredential) ValidateBasic() sdk.Error {
+	if msg.Credential.IsZero() || msg.Credential.IsNegative() {
+		return sdk.NewError(BulletinBoardCodespace, InvalidCredential,
+			"voter credential value cannot be zero or negative")
+	}
+	if len(strings.TrimSpace(msg.Reason)) == 0 {
+		return sdk.NewError(BulletinBoardCodespace, InvalidCredential,
+			"the reason for the revocation cannot be empty")
+	}
+	if len(msg.Reason) > MaxRevocationReasonLength {
+		return sdk.NewError(BulletinBoardCodespace, InvalidCredential,
+			"the reason for the revocation cannot be longer than %d characters",
+			MaxRevocationReasonLength)
+	}
+	if msg.Signer.Empty() {
+		return sdk.ErrInvalidAddress("missing signer address")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgRevokeVoterCredential) GetSignBytes() []byte {
+	return ModuleCdc.MustMarshalJSON(msg)
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgRevokeVoterCredential) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Signer}
+}
+
+//--------------------------------------------------------------------------------------------------
+// MsgReplaceVoterCredential
+
+var _ sdk.Msg = MsgReplaceVoterCredential{}
+
+// MsgReplaceVoterCredential defines the message for replacing a registered voter credential with
+// a new one, e.g. because the voter's private credentials leaked. The message must be signed by the
+// account that registered the old credential and carries a proof of knowledge of the old
+// credential's private credentials.
+type MsgReplaceVoterCredential struct {
+	Credential  crypto.Int                 `json:"u"`
+	Replacement crypto.Int                 `json:"new_u"`
+	Proof       crypto.RepresentationProof `json:"proof"`
+	Signer      sdk.AccAddress             `json:"signer"`
+}
+
+// NewMsgReplaceVoterCredential creates a new instance of the MsgReplaceVoterCredential message.
+func NewMsgReplaceVoterCredential(credential, replacement crypto.Int,
+	proof crypto.RepresentationProof, signer sdk.AccAddress) MsgReplaceVoterCredential {
+
+	return MsgReplaceVoterCredential{
+		Credential:  credential,
+		Replacement: replacement,
+		Proof:       proof,
+		Signer:      signer,
+	}
+}
+
+// ReplacementProofMessage returns the message the proof of a replacement is bound to. It consists
+// of the new credential and the signer such that the proof cannot be used to replace the old
+// credential with another one or by another account.
+func ReplacementProofMessage(replacement crypto.Int, signer sdk.AccAddress) []byte {
+	return ModuleCdc.MustMarshalBinaryBare(replacementProofMessage{replacement, signer})
+}
+
+type replacementProofMessage struct {
+	Replacement crypto.Int     `json:"new_u"`
+	Signer      sdk.AccAddress `json:"signer"`
+}
+
+// Route returns the name of the module.
+func (msg MsgReplaceVoterCredential) Route() string {
+	return BulletinBoardModuleName
+}
+
+// Type returns the action of the message.
+func (msg MsgReplaceVoterCredential) Type() string {
+	return "replace_voter_credential"
+}
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgReplaceVoterCredential) ValidateBasic() sdk.Error {
+	if msg.Credential.IsZero() || msg.Credential.IsNegative() ||
+		msg.Replacement.IsZero() || msg.Replacement.IsNegative() {
+		return sdk.NewError(BulletinBoardCodespace, InvalidCredential,
+			"voter credential value cannot be zero or negative")
+	}
+	if msg.Credential.BigInt().Cmp(msg.Replacement.BigInt()) == 0 {
+		return sdk.NewError(BulletinBoardCodespace, InvalidCredential,
+			"the new voter credential must differ from the old one")
+	}
+	if msg.Signer.Empty() {
+		return sdk.ErrInvalidAddress("missing signer address")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgReplaceVoterCredential) GetSignBytes() []byte {
+	return ModuleCdc.MustMarshalJSON(msg)
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgReplaceVoterCredential) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Signer}
+}
+
+//--------------------------------------------------------------------------------------------------
+// MsgAddToRoll and MsgRemoveFromRoll
+
+var _ sdk.Msg = MsgAddToRoll{}
+var _ sdk.Msg = MsgRemoveFromRoll{}
+
+// MsgAddToRoll defines the message for adding eligible accounts to the electoral roll. Only the
+// election administration may change the roll.
+type MsgAddToRoll struct {
+	Addresses []sdk.AccAddress `json:"addresses"`
+	Signer    sdk.AccAddress   `json:"signer"`
+}
+
+// NewMsgAddToRoll creates a new instance of the MsgAddToRoll message.
+func NewMsgAddToRoll(addrs []sdk.AccAddress, signer sdk.AccAddress) MsgAddToRoll {
+	return MsgAddToRoll{
+		Addresses: addrs,
+		Signer:    signer,
+	}
+}
+
+// Route returns the name of the module.
+func (msg MsgAddToRoll) Route() string {
+	return BulletinBoardModuleName
+}
+
+// Type returns the action of the message.
+func (msg MsgAddToRoll) Type() string {
+	return "add_to_roll"
+}
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgAddToRoll) ValidateBasic() sdk.Error {
+	return validateRollChange(msg.Addresses, msg.Signer)
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgAddToRoll) GetSignBytes() []byte {
+	return ModuleCdc.MustMarshalJSON(msg)
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgAddToRoll) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Signer}
+}
+
+// MsgRemoveFromRoll defines the message for removing accounts from the electoral roll, e.g.
+// because they turn out not to be eligible. Only accounts without a registered credential can be
+// removed. Only the election administration may change the roll.
+type MsgRemoveFromRoll struct {
+	Addresses []sdk.AccAddress `json:"addresses"`
+	Signer    sdk.AccAddress   `json:"signer"`
+}
+
+// NewMsgRemoveFromRoll creates a new instance of the MsgRemoveFromRoll message.
+func NewMsgRemoveFromRoll(addrs []sdk.AccAddress, signer sdk.AccAddress) MsgRemoveFromRoll {
+	return MsgRemoveFromRoll{
+		Addresses: addrs,
+		Signer:    signer,
+	}
+}
+
+// Route returns the name of the module.
+func (msg MsgRemoveFromRoll) Route() string {
+	return BulletinBoardModuleName
+}
+
+// Type returns the action of the message.
+func (msg MsgRemoveFromRoll) Type() string {
+	return "remove_from_roll"
+}
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgRemoveFromRoll) ValidateBasic() sdk.Error {
+	return validateRollChange(msg.Addresses, msg.Signer)
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgRemoveFromRoll) GetSignBytes() []byte {
+	return ModuleCdc.MustMarshalJSON(msg)
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgRemoveFromRoll) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Signer}
+}
+
+// validateRollChange checks that a change of the electoral roll lists at least one account, has
+// neither empty nor duplicate addresses and is signed.
+func validateRollChange(addrs []sdk.AccAddress, signer sdk.AccAddress) sdk.Error {
+	if len(addrs) == 0 {
+		return sdk.ErrInvalidAddress("no accounts given")
+	}
+	if err := ValidateRoll(addrs); err != nil {
+		return sdk.ErrInvalidAddress(err.Error())
+	}
+	if signer.Empty() {
+		return sdk.ErrInvalidAddress("missing signer address")
+	}
+	return nil
+}
+
 //--------------------------------------------------------------------------------------------------
 // MspPutBallot
 
@@ -89,6 +344,11 @@ func (msg MsgPutBallot) ValidateBasic() sdk.Error {
 	if len(msg.Ballot.V) == 0 {
 		return sdk.NewError(BulletinBoardCodespace, InvalidBallot, "vote cannot be empty")
 	}
+	if msg.Ballot.Version != crypto.TranscriptVersion {
+		return sdk.NewError(BulletinBoardCodespace, InvalidBallot,
+			"unsupported proof transcript version %d, expected %d", msg.Ballot.Version,
+			crypto.TranscriptVersion)
+	}
 	if msg.Ballot.UHat.IsZero() || msg.Ballot.UHat.IsNegative() {
 		return sdk.NewError(BulletinBoardCodespace, InvalidBallot,
 			"voter's election credential cannot be zero or negative")
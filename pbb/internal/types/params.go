@@ -1,14 +1,27 @@
 package types
 
 import (
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/params/subspace"
 	"github.com/csmuller/up-voting-system/crypto"
 	"math/big"
 	"strings"
 )
 
-const DefaultParamSpace = BulletinBoardModuleName
+const (
+	DefaultParamSpace = BulletinBoardModuleName
+
+	// DefaultGeneratorSeed is the public seed from which the default commitment generators are
+	// derived.
+	DefaultGeneratorSeed = "up-voting-system"
+
+	// MaxSecurityParam is the largest supported security parameter. The challenges of the double
+	// discrete log proof system are SHA-256 hashes and therefore provide at most 256 bits.
+	MaxSecurityParam = 256
+)
 
 var (
 	// Parameter keys
@@ -16,6 +29,11 @@ var (
 	CommQKey         = []byte("CommQ")
 	HKey             = []byte("HHat")
 	SecurityParamKey = []byte("SecurityParam")
+	ScheduleKey      = []byte("Schedule")
+	SeedKey          = []byte("GeneratorSeed")
+	SecurityLevelKey = []byte("SecurityLevel")
+	ElectionIDKey    = []byte("ElectionID")
+	AdminsKey        = []byte("Admins")
 )
 
 // Params implements the ParamSet interface
@@ -24,6 +42,11 @@ type Params struct {
 	CommQ         crypto.PedersenCommitmentScheme `json:"comm_q"`
 	HHat          crypto.Int                      `json:"h"` // election generator
 	SecurityParam int                             `json:"k"`
+	Schedule      ElectionSchedule                `json:"schedule"`
+	GeneratorSeed string                          `json:"seed"`           // seed of CommP's and CommQ's generators
+	SecurityLevel string                          `json:"security_level"` // name of the groups' preset
+	ElectionID    string                          `json:"election_id"`    // identifies the election ballots are bound to
+	Admins        []sdk.AccAddress                `json:"admins"`         // accounts of the election administration
 }
 
 // ParamSetPairs returns all the key/value pairs pairs of the bulletin board module's parameters.
@@ -33,15 +56,25 @@ func (p *Params) ParamSetPairs() subspace.ParamSetPairs {
 		{CommQKey, &p.CommQ},
 		{HKey, &p.HHat},
 		{SecurityParamKey, &p.SecurityParam},
+		{ScheduleKey, &p.Schedule},
+		{SeedKey, &p.GeneratorSeed},
+		{SecurityLevelKey, &p.SecurityLevel},
+		{ElectionIDKey, &p.ElectionID},
+		{AdminsKey, &p.Admins},
 	}
 }
 
 func (p Params) String() string {
 	var str strings.Builder
 	str.WriteString("Parameters: {\n")
+	str.WriteString(fmt.Sprintf("election ID: %s,\n", p.ElectionID))
+	str.WriteString(fmt.Sprintf("security level: %s,\n", p.SecurityLevel))
 	str.WriteString(fmt.Sprintf("commP: %s,\n", p.CommP.String()))
 	str.WriteString(fmt.Sprintf("commQ: %s,\n", p.CommQ.String()))
 	str.WriteString(fmt.Sprintf("HHat: %s,\n", p.HHat.String()))
+	str.WriteString(fmt.Sprintf("schedule: %s,\n", p.Schedule.String()))
+	str.WriteString(fmt.Sprintf("generator seed: %s,\n", p.GeneratorSeed))
+	str.WriteString(fmt.Sprintf("admins: %v,\n", p.Admins))
 	str.WriteString("}")
 	return str.String()
 }
@@ -50,33 +83,154 @@ func ParamKeyTable() subspace.KeyTable {
 	return subspace.NewKeyTable().RegisterParamSet(&Params{})
 }
 
-// NewParams creates a new Params object which does not set the election generator by choice.
+// HasElectionGenerator checks if the election generator HHat has already been derived. This is
+// the case once the registration phase is closed.
+func (p Params) HasElectionGenerator() bool {
+	return p.HHat.BigInt() != nil && p.HHat.BigInt().Sign() != 0
+}
+
+// IsAdmin checks if the given account belongs to the election administration.
+func (p Params) IsAdmin(addr sdk.AccAddress) bool {
+	for _, admin := range p.Admins {
+		if admin.Equals(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the parameters are usable by the UEP protocol. The groups G_p and G_q must
+// have prime moduli and orders, G_q must be a subgroup of Z*_p, i.e. p = b * q + 1, and all
+// generators must be generators of their group. CommP must have one and CommQ two message
+// generators. If the election generator is set, it must be a generator of G_q. The security
+// parameter k must satisfy 0 < k <= 256 and 2^k < p. The election ID and the addresses of the
+// admins cannot be empty.
+func (p Params) Validate() error {
+	if strings.TrimSpace(p.ElectionID) == "" {
+		return errors.New("the election ID cannot be empty, set it with set-election-id")
+	}
+	if err := p.CommP.Validate(); err != nil {
+		return fmt.Errorf("invalid comm_p: %v", err)
+	}
+	if err := p.CommQ.Validate(); err != nil {
+		return fmt.Errorf("invalid comm_q: %v", err)
+	}
+	gP, gQ, err := p.SchnorrGroups()
+	if err != nil {
+		return err
+	}
+	if gQ.Modulus.Cmp(gP.Order) != 0 {
+		return errors.New("the modulus of G_q must be equal to the order p of G_p")
+	}
+	if len(p.CommP.Hm) != 1 {
+		return fmt.Errorf("comm_p must have 1 message generator but has %d", len(p.CommP.Hm))
+	}
+	if len(p.CommQ.Hm) != 2 {
+		return fmt.Errorf("comm_q must have 2 message generators but has %d", len(p.CommQ.Hm))
+	}
+	if p.HasElectionGenerator() && !gQ.IsGenerator(p.HHat.BigInt()) {
+		return errors.New("the election generator h is not a generator of G_q")
+	}
+	if p.SecurityParam <= 0 || p.SecurityParam > MaxSecurityParam {
+		return fmt.Errorf("security parameter must be in [1, %d] but is %d", MaxSecurityParam,
+			p.SecurityParam)
+	}
+	if gP.Order.BitLen() <= p.SecurityParam {
+		return fmt.Errorf("order p of G_p must be bigger than 2^k for security parameter k = %d",
+			p.SecurityParam)
+	}
+	for _, admin := range p.Admins {
+		if admin.Empty() {
+			return errors.New("the address of an admin cannot be empty")
+		}
+	}
+	return nil
+}
+
+// Hash returns the SHA-256 hash of the binary encoding of the cryptographic parameters, i.e. the
+// commitment schemes with their groups, the security parameter, the generator seed and the
+// security level, and of the election ID. The schedule and the admins are not hashed such that
+// changing them does not invalidate proofs bound to the hash. The election generator is not
+// hashed either because it is derived during the election and part of the ballot proofs anyway.
+func (p Params) Hash() []byte {
+	hash := sha256.Sum256(ModuleCdc.MustMarshalBinaryBare(hashedParams{
+		CommP:         p.CommP,
+		CommQ:         p.CommQ,
+		SecurityParam: p.SecurityParam,
+		GeneratorSeed: p.GeneratorSeed,
+		SecurityLevel: p.SecurityLevel,
+		ElectionID:    p.ElectionID,
+	}))
+	return hash[:]
+}
+
+// hashedParams are the parameters covered by Params.Hash.
+type hashedParams struct {
+	CommP         crypto.PedersenCommitmentScheme
+	CommQ         crypto.PedersenCommitmentScheme
+	SecurityParam int
+	GeneratorSeed string
+	SecurityLevel string
+	ElectionID    string
+}
+
+// SchnorrGroups returns the groups G_p and G_q of CommP and CommQ. The UEP protocol commits to
+// elements of G_q in G_p, which requires both to be Schnorr groups. Returns an error if either of
+// the groups is of another kind.
+func (p Params) SchnorrGroups() (gP, gQ *crypto.GStarModPrime, err error) {
+	gP, okP := p.CommP.G.(*crypto.GStarModPrime)
+	gQ, okQ := p.CommQ.G.(*crypto.GStarModPrime)
+	if !okP || !okQ {
+		return nil, nil, errors.New("the groups G_p and G_q must be Schnorr groups")
+	}
+	return gP, gQ, nil
+}
+
+// VerifyGenerators checks that the generators of CommP and CommQ are the ones derived from the
+// generator seed.
+func (p Params) VerifyGenerators() error {
+	if !p.CommP.IsDerivedFromSeed(p.GeneratorSeed) {
+		return fmt.Errorf("the generators of comm_p are not derived from seed '%s'",
+			p.GeneratorSeed)
+	}
+	if !p.CommQ.IsDerivedFromSeed(p.GeneratorSeed) {
+		return fmt.Errorf("the generators of comm_q are not derived from seed '%s'",
+			p.GeneratorSeed)
+	}
+	return nil
+}
+
+// NewParams creates a new Params object. The election generator is usually not set (nil) because
+// it is derived when the registration phase is closed.
 func NewParams(commP crypto.PedersenCommitmentScheme, commQ crypto.PedersenCommitmentScheme,
-	h *big.Int, securityParam int) Params {
+	h *big.Int, securityParam int, schedule ElectionSchedule, seed string,
+	securityLevel string) Params {
 
 	return Params{
 		CommP:         commP,
 		CommQ:         commQ,
 		HHat:          crypto.NewInt(h),
 		SecurityParam: securityParam,
+		Schedule:      schedule,
+		GeneratorSeed: seed,
+		SecurityLevel: securityLevel,
 	}
 }
 
-// DefaultParams returns a default set of parameters.
-func DefaultParams() Params {
-	o, _ := new(big.Int).SetString(crypto.O, 10)
-	p, _ := new(big.Int).SetString(crypto.P, 10)
-	q, _ := new(big.Int).SetString(crypto.Q, 10)
+// NewParamsFromSeed creates a new Params object for the groups G_p and G_q. The generators of the
+// commitment schemes are derived from the given seed. The security level names the preset the
+// groups are taken from.
+func NewParamsFromSeed(gP, gQ crypto.Group, seed string, securityParam int,
+	schedule ElectionSchedule, securityLevel string) Params {
 
-	gP := crypto.NewGStarModPrime(o, p)
-	commP := crypto.NewPedersenCommitmentScheme(gP, gP.RandomGenerator(),
-		[]*big.Int{gP.RandomGenerator()})
-
-	gQ := crypto.NewGStarModPrime(p, q)
-	commQ := crypto.NewPedersenCommitmentScheme(gQ, gQ.RandomGenerator(),
-		[]*big.Int{gQ.RandomGenerator(), gQ.RandomGenerator()}) // comm_q can take two messages
-
-	h := gQ.RandomElement()
+	commP := crypto.NewPedersenCommitmentSchemeFromSeed(gP, seed, 1)
+	commQ := crypto.NewPedersenCommitmentSchemeFromSeed(gQ, seed, 2) // comm_q takes two messages
+	// The election generator is derived when the registration phase closes.
+	return NewParams(commP, commQ, nil, securityParam, schedule, seed, securityLevel)
+}
 
-	return NewParams(commP, commQ, h, crypto.SecurityParam)
+// DefaultParams returns a default set of parameters using the default security preset.
+func DefaultParams() Params {
+	preset, _ := GetSecurityPreset(DefaultSecurityLevel)
+	return preset.Params(DefaultGeneratorSeed, ElectionSchedule{})
 }
@@ -0,0 +1,102 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/csmuller/up-voting-system/crypto"
+)
+
+const (
+	// PresetLegacy1024 uses a 160 bit group G_q and a 1024 bit group G_p.
+	PresetLegacy1024 = "legacy-1024"
+	// PresetStd1024 uses a 1023 bit group G_q and a 1024 bit group G_p.
+	PresetStd1024 = "std-1024"
+	// PresetStd2048 uses a 2047 bit group G_q and a 2048 bit group G_p.
+	PresetStd2048 = "std-2048"
+
+	// SecurityLevelCustom is the security level of parameters with groups that are not taken
+	// from a preset, e.g. groups imported with 'pbbd import-params'.
+	SecurityLevelCustom = "custom"
+
+	// DefaultSecurityLevel is the preset used by the default parameters.
+	DefaultSecurityLevel = PresetLegacy1024
+)
+
+// SecurityPreset is a named set of groups G_q and G_p together with the security parameter of
+// the double discrete log proof system.
+type SecurityPreset struct {
+	Name          string
+	Q             string // Order of G_q.
+	P             string // Order of G_p and modulus of G_q.
+	O             string // Modulus of G_p.
+	SecurityParam int
+}
+
+// SecurityPresets lists all available presets with the groups defined in the crypto package.
+var SecurityPresets = []SecurityPreset{
+	{PresetLegacy1024, crypto.Q, crypto.P, crypto.O, crypto.SecurityParam},
+	{PresetStd1024, crypto.Q1, crypto.P1, crypto.O1, crypto.SecurityParam},
+	{PresetStd2048, crypto.Q2, crypto.P2, crypto.O2, crypto.SecurityParam2048},
+}
+
+// GetSecurityPreset returns the preset with the given name.
+func GetSecurityPreset(name string) (SecurityPreset, error) {
+	for _, preset := range SecurityPresets {
+		if preset.Name == name {
+			return preset, nil
+		}
+	}
+	return SecurityPreset{}, fmt.Errorf("unknown security preset '%s', available presets are %s",
+		name, strings.Join(SecurityPresetNames(), ", "))
+}
+
+// SecurityPresetNames returns the names of all available presets.
+func SecurityPresetNames() []string {
+	names := make([]string, len(SecurityPresets))
+	for i, preset := range SecurityPresets {
+		names[i] = preset.Name
+	}
+	return names
+}
+
+// Groups returns the groups G_p and G_q of this preset.
+func (sp SecurityPreset) Groups() (gP crypto.GStarModPrime, gQ crypto.GStarModPrime) {
+	o, _ := new(big.Int).SetString(sp.O, 10)
+	p, _ := new(big.Int).SetString(sp.P, 10)
+	q, _ := new(big.Int).SetString(sp.Q, 10)
+	return crypto.NewGStarModPrime(o, p), crypto.NewGStarModPrime(p, q)
+}
+
+// Params creates new parameters with this preset's groups and security parameter. The
+// commitment generators are derived from the given seed.
+func (sp SecurityPreset) Params(seed string, schedule ElectionSchedule) Params {
+	gP, gQ := sp.Groups()
+	return NewParamsFromSeed(&gP, &gQ, seed, sp.SecurityParam, schedule, sp.Name)
+}
+
+// VerifySecurityLevel checks that the groups and the security parameter correspond to the preset
+// named by the security level. Parameters with a custom security level are not checked.
+func (p Params) VerifySecurityLevel() error {
+	if p.SecurityLevel == SecurityLevelCustom {
+		return nil
+	}
+	preset, err := GetSecurityPreset(p.SecurityLevel)
+	if err != nil {
+		return err
+	}
+	gP, gQ, err := p.SchnorrGroups()
+	if err != nil {
+		return err
+	}
+	presetGP, presetGQ := preset.Groups()
+	if !gP.Equal(presetGP) || !gQ.Equal(presetGQ) {
+		return fmt.Errorf("the groups do not correspond to security preset '%s'", preset.Name)
+	}
+	if p.SecurityParam != preset.SecurityParam {
+		return fmt.Errorf("security parameter %d does not correspond to security preset '%s'",
+			p.SecurityParam, preset.Name)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/csmuller/up-voting-system/crypto"
 	"strings"
 )
@@ -10,8 +11,9 @@ import (
 // Voter Credentials
 
 type QueryResVoterCredential struct {
-	Credential  crypto.Int `json:"credential"`
-	BlockHeight int64      `json:"block_height"`
+	Credential  crypto.Int     `json:"credential"`
+	Registrant  sdk.AccAddress `json:"registrant"`
+	BlockHeight int64          `json:"block_height"`
 }
 
 type QueryResVoterCredentials []QueryResVoterCredential
@@ -27,7 +29,23 @@ func (credentials QueryResVoterCredentials) String() string {
 }
 
 func (credential QueryResVoterCredential) String() string {
-	return fmt.Sprintf("%s posted at block height %d\n",
-		credential.BlockHeight,
-		credential.Credential.String())
+	return fmt.Sprintf("%s posted by %s at block height %d\n",
+		credential.Credential.String(),
+		credential.Registrant,
+		credential.BlockHeight)
+}
+
+//--------------------------------------------------------------------------------------------------
+// Election Phase
+
+type QueryResElectionPhase struct {
+	Phase       ElectionPhase    `json:"phase"`
+	Deadline    string           `json:"deadline"` // Description of when the current phase ends.
+	Schedule    ElectionSchedule `json:"schedule"`
+	BlockHeight int64            `json:"block_height"` // Height at which the phase was queried.
+}
+
+func (res QueryResElectionPhase) String() string {
+	return fmt.Sprintf("Election is in the %s phase at block height %d, the phase ends at: %s",
+		res.Phase, res.BlockHeight, res.Deadline)
 }
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/csmuller/up-voting-system/crypto"
+)
+
+// RegistrationCheck is the result of checking a voter's public credential u against the bulletin
+// board. A voter can only cast a valid ballot if u is a root of the credential polynomial, i.e.
+// P(u) = 0. Since the polynomial is built from the registered credentials when the registration
+// phase closes, u is a root if and only if it is registered. Any other outcome is a discrepancy on
+// the bulletin board.
+type RegistrationCheck struct {
+	Credential  crypto.Int    `json:"credential"`
+	PolyBuilt   bool          `json:"poly_built"`   // The credential polynomial has been built.
+	IsRoot      bool          `json:"is_root"`      // P(u) = 0
+	Registered  bool          `json:"registered"`   // u is in the list of voter credentials.
+	BlockHeight int64         `json:"block_height"` // Height at which u was registered.
+	Phase       ElectionPhase `json:"phase"`
+	CanVote     bool          `json:"can_vote"`
+	Message     string        `json:"message"`
+}
+
+// NewRegistrationCheck checks the given public credential against the credential polynomial and
+// the list of registered voter credentials in the given election phase. The polynomial is nil if
+// it has not been built yet, in which case only the list of credentials is checked.
+func NewRegistrationCheck(u crypto.Int, poly *crypto.Polynomial,
+	credentials QueryResVoterCredentials, phase ElectionPhase) RegistrationCheck {
+
+	check := RegistrationCheck{
+		Credential: u,
+		PolyBuilt:  poly != nil,
+		IsRoot:     poly != nil && poly.Evaluate(u.BigInt()).Sign() == 0,
+		Phase:      phase,
+	}
+	for _, c := range credentials {
+		if c.Credential.BigInt().Cmp(u.BigInt()) == 0 {
+			check.Registered = true
+			check.BlockHeight = c.BlockHeight
+			break
+		}
+	}
+
+	switch {
+	case !check.Registered && !check.IsRoot:
+		check.Message = "the credential is not registered, the voter cannot cast a ballot"
+	case !check.PolyBuilt:
+		// The polynomial is built from all registered credentials when the registration closes.
+		check.CanVote = true
+		check.Message = fmt.Sprintf("the credential was registered at block height %d, the voter "+
+			"can cast a ballot once the voting phase starts", check.BlockHeight)
+	case !check.IsRoot:
+		check.Message = fmt.Sprintf("the credential was registered at block height %d but is not "+
+			"a root of the credential polynomial, the voter cannot cast a valid ballot",
+			check.BlockHeight)
+	case !check.Registered:
+		check.Message = "the credential is a root of the credential polynomial but is not " +
+			"registered"
+	case phase == PhaseVoting:
+		check.CanVote = true
+		check.Message = fmt.Sprintf("the credential was registered at block height %d, the voter "+
+			"can cast a ballot", check.BlockHeight)
+	default:
+		check.Message = fmt.Sprintf("the credential was registered at block height %d but the "+
+			"voting phase has ended", check.BlockHeight)
+	}
+	return check
+}
+
+func (c RegistrationCheck) String() string {
+	isRoot := "not built yet"
+	if c.PolyBuilt {
+		isRoot = fmt.Sprintf("%t", c.IsRoot)
+	}
+	return fmt.Sprintf("RegistrationCheck: {credential: %s, P(u) = 0: %s, registered: %t, "+
+		"phase: %s, can vote: %t}\n%s", c.Credential, isRoot, c.Registered, c.Phase, c.CanVote,
+		c.Message)
+}
@@ -0,0 +1,122 @@
+package types
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// VerificationReport is the report of the verification of all ballots of an election. It lists
+// the result of every ballot in the order in which the ballots were retrieved.
+type VerificationReport struct {
+	Ballots []BallotReport      `json:"ballots"`
+	Summary VerificationSummary `json:"summary"`
+}
+
+// BallotReport is the verification result of a single ballot. A ballot is counted if all of its
+// proofs are valid and no earlier ballot was cast with the same election credential.
+type BallotReport struct {
+	Index                 int         `json:"index"`
+	UHat                  string      `json:"u_hat"`
+	Vote                  string      `json:"vote"`
+	PolyEvalProof         ProofStatus `json:"poly_eval_proof"`
+	DDLogProof            ProofStatus `json:"ddlog_proof"`
+	PreimageEqualityProof ProofStatus `json:"preimage_equality_proof"`
+	Valid                 bool        `json:"valid"`
+	Duplicate             bool        `json:"duplicate"`
+	Counted               bool        `json:"counted"`
+	Reason                string      `json:"reason,omitempty"`
+}
+
+// VerificationSummary summarizes a verification report.
+type VerificationSummary struct {
+	Total      int            `json:"total"`
+	Valid      int            `json:"valid"`
+	Invalid    int            `json:"invalid"`   // Well-formed ballots with an invalid proof.
+	Malformed  int            `json:"malformed"` // Ballots that could not be verified.
+	Duplicates int            `json:"duplicates"`
+	Counted    int            `json:"counted"`
+	Votes      map[string]int `json:"votes"` // Number of counted ballots per vote.
+}
+
+// NewVerificationReport creates the report of the given verification results. Every ballot with
+// the same election credential as an earlier ballot is reported as a duplicate. The bulletin board
+// never stores two ballots with the same election credential, so any duplicate is a discrepancy.
+func NewVerificationReport(results []BallotVerification) VerificationReport {
+	report := VerificationReport{
+		Ballots: make([]BallotReport, len(results)),
+		Summary: VerificationSummary{Total: len(results), Votes: make(map[string]int)},
+	}
+	seen := make(map[string]bool)
+	for i, r := range results {
+		uHat := r.Ballot.UHat.String()
+		b := BallotReport{
+			Index:                 i,
+			UHat:                  uHat,
+			Vote:                  r.Ballot.V,
+			PolyEvalProof:         r.Proofs[0],
+			DDLogProof:            r.Proofs[1],
+			PreimageEqualityProof: r.Proofs[2],
+			Valid:                 r.Valid,
+			Duplicate:             seen[uHat],
+		}
+		seen[uHat] = true
+		b.Counted = b.Valid && !b.Duplicate
+
+		s := &report.Summary
+		switch {
+		case r.Err != nil:
+			b.Reason = r.Err.Error()
+			s.Malformed++
+		case !r.Valid:
+			b.Reason = "invalid proof"
+			s.Invalid++
+		default:
+			s.Valid++
+		}
+		if b.Duplicate {
+			if b.Reason == "" {
+				b.Reason = "duplicate election credential"
+			}
+			s.Duplicates++
+		}
+		if b.Counted {
+			s.Counted++
+			s.Votes[b.Vote]++
+		}
+		report.Ballots[i] = b
+	}
+	return report
+}
+
+// HasDiscrepancy checks if any ballot of the report is invalid, malformed or a duplicate.
+func (s VerificationSummary) HasDiscrepancy() bool {
+	return s.Invalid > 0 || s.Malformed > 0 || s.Duplicates > 0
+}
+
+func (s VerificationSummary) String() string {
+	return fmt.Sprintf("VerificationSummary: {total: %d, valid: %d, invalid: %d, malformed: %d, "+
+		"duplicates: %d, counted: %d, votes: %v}", s.Total, s.Valid, s.Invalid, s.Malformed,
+		s.Duplicates, s.Counted, s.Votes)
+}
+
+// WriteCSV writes the ballots of the report as CSV with a header row to the given writer.
+func (r VerificationReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"index", "u_hat", "vote", "poly_eval_proof", "ddlog_proof",
+		"preimage_equality_proof", "valid", "duplicate", "counted", "reason"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, b := range r.Ballots {
+		record := []string{strconv.Itoa(b.Index), b.UHat, b.Vote, string(b.PolyEvalProof),
+			string(b.DDLogProof), string(b.PreimageEqualityProof), strconv.FormatBool(b.Valid),
+			strconv.FormatBool(b.Duplicate), strconv.FormatBool(b.Counted), b.Reason}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
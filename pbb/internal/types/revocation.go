@@ -0,0 +1,56 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/csmuller/up-voting-system/crypto"
+)
+
+// Revocation is the record of a voter credential revoked by the election administration. A
+// revoked credential is removed from the registered credentials and cannot be registered again.
+type Revocation struct {
+	Credential     crypto.Int     `json:"credential"`
+	Reason         string         `json:"reason"`
+	Admin          sdk.AccAddress `json:"admin"`          // Account that revoked the credential.
+	RegisteredAt   int64          `json:"registered_at"`  // Height of the registration.
+	BlockHeight    int64          `json:"block_height"`   // Height of the revocation.
+	Reregistration bool           `json:"reregistration"` // Registrant may register again.
+}
+
+// NewRevocation creates a new record of a revoked voter credential.
+func NewRevocation(credential crypto.Int, reason string, admin sdk.AccAddress, registeredAt,
+	blockHeight int64, reregistration bool) Revocation {
+
+	return Revocation{
+		Credential:     credential,
+		Reason:         reason,
+		Admin:          admin,
+		RegisteredAt:   registeredAt,
+		BlockHeight:    blockHeight,
+		Reregistration: reregistration,
+	}
+}
+
+func (r Revocation) String() string {
+	str := fmt.Sprintf("%s registered at block height %d, revoked at block height %d by %s: %s",
+		r.Credential, r.RegisteredAt, r.BlockHeight, r.Admin, r.Reason)
+	if r.Reregistration {
+		str += " (re-registration allowed)"
+	}
+	return str
+}
+
+// Revocations is the list of all revoked voter credentials.
+type Revocations []Revocation
+
+func (revocations Revocations) String() string {
+	var str strings.Builder
+	str.WriteString("Revocations: {\n")
+	for _, r := range revocations {
+		str.WriteString(fmt.Sprintf("%s,\n", r.String()))
+	}
+	str.WriteString("}")
+	return str.String()
+}
@@ -0,0 +1,126 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/csmuller/up-voting-system/crypto"
+)
+
+// RollEntry is the entry of an eligible account in the electoral roll. Every eligible account can
+// register exactly one voter credential. If the credential is revoked, the entry keeps it and the
+// account cannot register another one, unless the revocation explicitly allowed re-registration.
+type RollEntry struct {
+	Address     sdk.AccAddress `json:"address"`
+	Credential  crypto.Int     `json:"credential"`   // Registered credential, 0 if none.
+	BlockHeight int64          `json:"block_height"` // Height of the credential's registration.
+	Revoked     bool           `json:"revoked"`      // Whether the credential has been revoked.
+}
+
+// NewRollEntry creates a new entry of an eligible account that has not registered a credential
+// yet.
+func NewRollEntry(addr sdk.AccAddress) RollEntry {
+	return RollEntry{
+		Address: addr,
+	}
+}
+
+// HasCredential checks if the account has registered a voter credential that has not been
+// revoked.
+func (e RollEntry) HasCredential() bool {
+	return !e.Credential.IsZero() && !e.Revoked
+}
+
+// CanRegister checks if the account can register a voter credential, i.e. if it has neither
+// registered a credential nor had one revoked.
+func (e RollEntry) CanRegister() bool {
+	return e.Credential.IsZero()
+}
+
+func (e RollEntry) String() string {
+	if e.CanRegister() {
+		return fmt.Sprintf("%s has not registered a credential", e.Address)
+	}
+	if e.Revoked {
+		return fmt.Sprintf("%s registered %s at block height %d, which has been revoked",
+			e.Address, e.Credential, e.BlockHeight)
+	}
+	return fmt.Sprintf("%s registered %s at block height %d", e.Address, e.Credential,
+		e.BlockHeight)
+}
+
+// Roll is the electoral roll, i.e. the list of all eligible accounts.
+type Roll []RollEntry
+
+// Addresses returns the addresses of the accounts on the roll.
+func (roll Roll) Addresses() []sdk.AccAddress {
+	addrs := make([]sdk.AccAddress, len(roll))
+	for i, e := range roll {
+		addrs[i] = e.Address
+	}
+	return addrs
+}
+
+func (roll Roll) String() string {
+	var str strings.Builder
+	str.WriteString("Roll: {\n")
+	for _, e := range roll {
+		str.WriteString(fmt.Sprintf("%s,\n", e.String()))
+	}
+	str.WriteString("}")
+	return str.String()
+}
+
+// ValidateRoll checks that the given list of eligible accounts has neither empty nor duplicate
+// addresses.
+func ValidateRoll(addrs []sdk.AccAddress) error {
+	seen := make(map[string]bool, len(addrs))
+	for _, addr := range addrs {
+		if addr.Empty() {
+			return errors.New("the address of an eligible account cannot be empty")
+		}
+		if seen[string(addr)] {
+			return fmt.Errorf("the account %s is listed more than once", addr)
+		}
+		seen[string(addr)] = true
+	}
+	return nil
+}
+
+// Turnout summarizes how many of the eligible accounts have registered a voter credential and how
+// many ballots have been cast. Ballots cannot be linked to accounts, so the voting turnout is the
+// number of ballots relative to the number of eligible accounts.
+type Turnout struct {
+	Phase       ElectionPhase `json:"phase"`
+	Eligible    int           `json:"eligible"`     // Number of accounts on the electoral roll.
+	Registered  int           `json:"registered"`   // Number of registered voter credentials.
+	Ballots     int           `json:"ballots"`      // Number of ballots cast.
+	BlockHeight int64         `json:"block_height"` // Height at which the turnout was queried.
+}
+
+// RegistrationRate returns the share of eligible accounts that have registered a credential.
+// Returns 0 if the roll is empty.
+func (t Turnout) RegistrationRate() float64 {
+	return rate(t.Registered, t.Eligible)
+}
+
+// VotingRate returns the number of ballots relative to the number of eligible accounts. Returns 0
+// if the roll is empty.
+func (t Turnout) VotingRate() float64 {
+	return rate(t.Ballots, t.Eligible)
+}
+
+func rate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+func (t Turnout) String() string {
+	return fmt.Sprintf("Turnout at block height %d in the %s phase: %d eligible accounts, %d "+
+		"registered credentials (%.1f%%), %d ballots (%.1f%%)", t.BlockHeight, t.Phase,
+		t.Eligible, t.Registered, 100*t.RegistrationRate(), t.Ballots, 100*t.VotingRate())
+}
@@ -0,0 +1,204 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/csmuller/up-voting-system/crypto"
+)
+
+// verifyChunkSize is the maximal number of ballots a worker verifies in one batch.
+const verifyChunkSize = 32
+
+// VerifyOptions configures the verification of ballots with VerifyBallots.
+type VerifyOptions struct {
+	// Workers is the number of ballots verified in parallel. Defaults to the number of CPUs.
+	Workers int
+	// Progress is called with the number of verified ballots and the total number of ballots
+	// whenever a chunk of ballots has been verified. It is never called concurrently.
+	Progress func(verified, total int)
+}
+
+// ProofStatus is the verification result of a single proof of a ballot.
+type ProofStatus string
+
+const (
+	ProofValid       ProofStatus = "valid"
+	ProofInvalid     ProofStatus = "invalid"
+	ProofMalformed   ProofStatus = "malformed"
+	ProofNotVerified ProofStatus = "not verified" // The ballot is malformed apart from its proofs.
+)
+
+// BallotVerification is the verification result of a single ballot.
+type BallotVerification struct {
+	Ballot Ballot
+	Valid  bool
+	// Status of the polynomial evaluation, the double discrete log and the pre-image equality
+	// proof, in this order.
+	Proofs [3]ProofStatus
+	// Err describes why a ballot could not be verified, e.g. because it is malformed. It is nil for
+	// well-formed ballots, whether their proofs are valid or not.
+	Err error
+}
+
+// VerifyBallots verifies the proofs of the given ballots under the given parameters, credential
+// polynomial and chain ID. The ballots are split into chunks which are batch verified by a pool
+// of workers. The results are in the same order as the ballots. An error is only returned if the
+// proof systems cannot be set up with the given parameters.
+func VerifyBallots(ballots []Ballot, params Params, poly crypto.Polynomial, chainID string,
+	opts VerifyOptions) ([]BallotVerification, error) {
+
+	v, err := newBallotVerifier(params, poly, chainID)
+	if err != nil {
+		return nil, err
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	chunkSize := (len(ballots) + workers - 1) / workers
+	if chunkSize > verifyChunkSize {
+		chunkSize = verifyChunkSize
+	}
+
+	results := make([]BallotVerification, len(ballots))
+	chunks := make(chan int)
+	verified := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range chunks {
+				end := start + chunkSize
+				if end > len(ballots) {
+					end = len(ballots)
+				}
+				v.verify(ballots[start:end], results[start:end])
+				verified <- end - start
+			}
+		}()
+	}
+	go func() {
+		for start := 0; start < len(ballots); start += chunkSize {
+			chunks <- start
+		}
+		close(chunks)
+		wg.Wait()
+		close(verified)
+	}()
+
+	done := 0
+	for n := range verified {
+		done += n
+		if opts.Progress != nil {
+			opts.Progress(done, len(ballots))
+		}
+	}
+	return results, nil
+}
+
+// ballotVerifier holds the proof systems to verify ballots with. They are safe for concurrent
+// use and shared by all workers.
+type ballotVerifier struct {
+	params Params
+	ps1    crypto.PolynomialEvaluationProofSystem
+	ps2    crypto.DoubleDiscreteLogProofSystem
+	ps3    crypto.PreimageEqualityProofSystem
+}
+
+func newBallotVerifier(params Params, poly crypto.Polynomial, chainID string) (*ballotVerifier,
+	error) {
+
+	if !params.HasElectionGenerator() {
+		return nil, errors.New("election generator has not been defined yet")
+	}
+	ps1, err := crypto.NewPolynomialEvaluationProofSystem(params.CommP, poly)
+	if err != nil {
+		return nil, err
+	}
+	ps2, err := crypto.NewDoubleDiscreteLogProofSystem(params.CommP, params.CommQ,
+		params.SecurityParam)
+	if err != nil {
+		return nil, err
+	}
+	ps3, err := crypto.NewPreimageEqualityProofSystem(params.HHat.BigInt(), params.CommQ)
+	if err != nil {
+		return nil, err
+	}
+	binding := NewBallotBinding(chainID, params).Bytes()
+	ps1.Context, ps2.Context, ps3.Context = binding, binding, binding
+	return &ballotVerifier{params: params, ps1: ps1, ps2: ps2, ps3: ps3}, nil
+}
+
+// verify batch verifies the given ballots and stores their results in the given slice. The
+// proofs of ballots that fail the batch verification are verified one by one to find out which of
+// them are invalid.
+func (v *ballotVerifier) verify(ballots []Ballot, results []BallotVerification) {
+	batch := crypto.NewBatchVerifier()
+	batchIdx := make([]int, len(ballots))
+	eqs := make([][]crypto.Equations, len(ballots))
+	for i, b := range ballots {
+		results[i] = BallotVerification{Ballot: b}
+		batchIdx[i] = -1
+		eqs[i], results[i].Err = v.equations(b, &results[i].Proofs)
+		if results[i].Err == nil {
+			batchIdx[i] = batch.Add(eqs[i]...)
+		}
+	}
+	invalid := make(map[int]bool)
+	for _, idx := range batch.Verify() {
+		invalid[idx] = true
+	}
+	for i := range ballots {
+		results[i].Valid = batchIdx[i] >= 0 && !invalid[batchIdx[i]]
+		for j, status := range results[i].Proofs {
+			if status != "" {
+				continue
+			}
+			if results[i].Valid || eqs[i][j].Hold() {
+				results[i].Proofs[j] = ProofValid
+			} else {
+				results[i].Proofs[j] = ProofInvalid
+			}
+		}
+	}
+}
+
+// equations validates the given ballot and returns the verification equations of its proofs. The
+// status of proofs that cannot be verified is set in the given array. An error is returned if any
+// of them cannot be verified.
+func (v *ballotVerifier) equations(b Ballot, status *[3]ProofStatus) ([]crypto.Equations,
+	error) {
+
+	// Ballots with proofs of another transcript version cannot be verified.
+	var err error
+	if b.Version != crypto.TranscriptVersion {
+		err = fmt.Errorf("ballot has transcript version %d instead of %d", b.Version,
+			crypto.TranscriptVersion)
+	} else if sdkErr := b.ValidateElements(v.params); sdkErr != nil {
+		err = sdkErr
+	}
+	if err != nil {
+		*status = [3]ProofStatus{ProofNotVerified, ProofNotVerified, ProofNotVerified}
+		return nil, err
+	}
+
+	eqs := make([]crypto.Equations, 3)
+	errs := make([]error, 3)
+	eqs[0], errs[0] = v.ps1.Equations(b.Proof1, b.C.BigInt(), b.V)
+	eqs[1], errs[1] = v.ps2.Equations(b.Proof2, b.C.BigInt(), b.D.BigInt(), b.V)
+	eqs[2], errs[2] = v.ps3.Equations(b.Proof3, b.D.BigInt(), b.UHat.BigInt(), b.V)
+	names := []string{"polynomial evaluation", "double discrete log", "pre-image equality"}
+	for i := range errs {
+		if errs[i] != nil {
+			status[i] = ProofMalformed
+			if err == nil {
+				err = fmt.Errorf("malformed %s proof: %v", names[i], errs[i])
+			}
+		}
+	}
+	return eqs, err
+}
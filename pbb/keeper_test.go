@@ -0,0 +1,156 @@
+package pbb
+
+import (
+	"testing"
+
+	"github.com/csmuller/up-voting-system/crypto"
+	"github.com/csmuller/up-voting-system/pbb/internal/types"
+)
+
+func TestRevokedCredentialCannotBeReused(t *testing.T) {
+	in := newTestInput(t, 2)
+	u := crypto.NewInt(in.newCredential().U)
+	if err := in.keeper.StoreVoterCredential(in.ctx, u, in.voters[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.keeper.RevokeVoterCredential(in.ctx, u, "not eligible", in.admin,
+		false); err != nil {
+		t.Fatal(err)
+	}
+	checkError(t, in.keeper.StoreVoterCredential(in.ctx, u, in.voters[1]),
+		types.InvalidCredential, "registration of a revoked credential by another account")
+	other := crypto.NewInt(in.newCredential().U)
+	checkError(t, in.keeper.StoreVoterCredential(in.ctx, other, in.voters[0]),
+		types.AlreadyRegistered, "registration by an account whose credential was revoked")
+	checkError(t, in.keeper.RevokeVoterCredential(in.ctx, u, "not eligible", in.admin, false),
+		types.InvalidCredential, "second revocation of a credential")
+
+	entry, _ := in.keeper.GetRollEntry(in.ctx, in.voters[0])
+	if entry == nil || !entry.Revoked || entry.HasCredential() || entry.CanRegister() {
+		t.Errorf("roll entry must be marked as revoked: %v", entry)
+	}
+	if err := in.keeper.RemoveFromRoll(in.ctx, in.voters[0]); err != nil {
+		t.Errorf("account whose credential was revoked must be removable: %v", err)
+	}
+}
+
+func TestRevocationAllowingReregistration(t *testing.T) {
+	in := newTestInput(t, 1)
+	u := crypto.NewInt(in.newCredential().U)
+	if err := in.keeper.StoreVoterCredential(in.ctx, u, in.voters[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.keeper.RevokeVoterCredential(in.ctx, u, "registered by mistake", in.admin,
+		true); err != nil {
+		t.Fatal(err)
+	}
+	checkError(t, in.keeper.StoreVoterCredential(in.ctx, u, in.voters[0]),
+		types.InvalidCredential, "re-registration of the revoked credential")
+	other := crypto.NewInt(in.newCredential().U)
+	if err := in.keeper.StoreVoterCredential(in.ctx, other, in.voters[0]); err != nil {
+		t.Errorf("registration of a new credential must succeed: %v", err)
+	}
+	revocations, _ := in.keeper.GetRevocations(in.ctx)
+	if len(revocations) != 1 || !revocations[0].Reregistration {
+		t.Errorf("revocation must record that re-registration is allowed: %v", revocations)
+	}
+}
+
+func TestReplacedCredentialCannotBeReused(t *testing.T) {
+	in := newTestInput(t, 2)
+	u := crypto.NewInt(in.newCredential().U)
+	replacement := crypto.NewInt(in.newCredential().U)
+	if err := in.keeper.StoreVoterCredential(in.ctx, u, in.voters[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.keeper.ReplaceVoterCredential(in.ctx, u, replacement,
+		in.voters[0]); err != nil {
+		t.Fatal(err)
+	}
+	checkError(t, in.keeper.StoreVoterCredential(in.ctx, u, in.voters[1]),
+		types.InvalidCredential, "registration of a replaced credential")
+	checkError(t, in.keeper.ReplaceVoterCredential(in.ctx, replacement, u, in.voters[0]),
+		types.InvalidCredential, "replacement with a replaced credential")
+	checkError(t, in.keeper.ReplaceVoterCredential(in.ctx, u,
+		crypto.NewInt(in.newCredential().U), in.voters[0]),
+		types.InvalidCredential, "replacement of a replaced credential")
+
+	entry, _ := in.keeper.GetRollEntry(in.ctx, in.voters[0])
+	if entry == nil || entry.Credential.BigInt().Cmp(replacement.BigInt()) != 0 {
+		t.Errorf("roll entry must hold the replacement: %v", entry)
+	}
+}
+
+func TestReplaceVoterCredentialRequiresRegistrant(t *testing.T) {
+	in := newTestInput(t, 2)
+	u := crypto.NewInt(in.newCredential().U)
+	if err := in.keeper.StoreVoterCredential(in.ctx, u, in.voters[0]); err != nil {
+		t.Fatal(err)
+	}
+	replacement := crypto.NewInt(in.newCredential().U)
+	checkError(t, in.keeper.ReplaceVoterCredential(in.ctx, u, replacement, in.voters[1]),
+		types.NotRegistrant, "replacement by another account")
+	if registration, _ := in.keeper.GetVoterCredential(in.ctx, u); registration == nil ||
+		!registration.Registrant.Equals(in.voters[0]) {
+		t.Error("rejected replacement must leave the credential registered")
+	}
+	if registration, _ := in.keeper.GetVoterCredential(in.ctx, replacement); registration != nil {
+		t.Error("rejected replacement must not be registered")
+	}
+}
+
+func TestReplaceVoterCredentialRequiresRoll(t *testing.T) {
+	in := newTestInput(t, 1)
+	u := crypto.NewInt(in.newCredential().U)
+	in.keeper.SetVoterCredential(in.ctx, u, types.NewCredentialRegistration(in.outsider,
+		in.ctx.BlockHeight()))
+	replacement := crypto.NewInt(in.newCredential().U)
+	checkError(t, in.keeper.ReplaceVoterCredential(in.ctx, u, replacement, in.outsider),
+		types.NotEligible, "replacement by an account that is not on the roll")
+	if entry, _ := in.keeper.GetRollEntry(in.ctx, in.outsider); entry != nil {
+		t.Errorf("rejected replacement must not add the account to the roll: %s", entry)
+	}
+	if registration, _ := in.keeper.GetVoterCredential(in.ctx, replacement); registration != nil {
+		t.Error("rejected replacement must not be registered")
+	}
+}
+
+func TestStoreVoterCredentialOncePerAccount(t *testing.T) {
+	in := newTestInput(t, 2)
+	u := crypto.NewInt(in.newCredential().U)
+	if err := in.keeper.StoreVoterCredential(in.ctx, u, in.voters[0]); err != nil {
+		t.Fatal(err)
+	}
+	other := crypto.NewInt(in.newCredential().U)
+	checkError(t, in.keeper.StoreVoterCredential(in.ctx, other, in.voters[0]),
+		types.AlreadyRegistered, "second registration by the same account")
+	checkError(t, in.keeper.StoreVoterCredential(in.ctx, u, in.voters[1]),
+		types.InvalidCredential, "registration of a registered credential by another account")
+
+	entry, err := in.keeper.GetRollEntry(in.ctx, in.voters[0])
+	if err != nil || entry == nil || entry.Credential.BigInt().Cmp(u.BigInt()) != 0 {
+		t.Errorf("roll entry must hold the registered credential: %v", entry)
+	}
+	if in.keeper.GetTurnout(in.ctx).Registered != 1 {
+		t.Error("only one credential must be registered")
+	}
+}
+
+func TestStoreVoterCredentialRequiresRoll(t *testing.T) {
+	in := newTestInput(t, 1)
+	u := crypto.NewInt(in.newCredential().U)
+	checkError(t, in.keeper.StoreVoterCredential(in.ctx, u, in.outsider), types.NotEligible,
+		"registration by an account not on the roll")
+	if registration, _ := in.keeper.GetVoterCredential(in.ctx, u); registration != nil {
+		t.Error("credential of an account not on the roll must not be stored")
+	}
+
+	if err := in.keeper.AddToRoll(in.ctx, in.outsider); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.keeper.StoreVoterCredential(in.ctx, u, in.outsider); err != nil {
+		t.Errorf("registration by an account added to the roll must succeed: %v", err)
+	}
+	checkError(t, in.keeper.RemoveFromRoll(in.ctx, in.outsider), types.AlreadyRegistered,
+		"removal of an account with a registered credential")
+}
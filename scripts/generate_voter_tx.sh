@@ -11,6 +11,8 @@
 # The last argument denotes the next free sequence number of the voter account. If you have
 # already sent 100 transaction with the account then the next sequence number is 100.
 # The generated ballots are stored in the vcli home directory.
+# Every account on the electoral roll can register only one credential, so only the first
+# transaction is accepted unless the voter account is replaced between the transactions.
 
 if (( $# < 4 )); then
     echo "./genvoter.sh [begin range] [end range] [home number] [sequence number]."
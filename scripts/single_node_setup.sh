@@ -24,6 +24,10 @@ vcli config trust-node true
 
 pbbd add-genesis-account $(acli keys show admin -a) 100000000stake,1000foo
 # pbbd add-genesis-account $(vcli keys show voter -a) 1foo
+pbbd set-election-id pbb-test
+pbbd set-schedule --registration-end-height 100 --voting-end-height 200
+pbbd add-admin $(acli keys show admin -a)
+pbbd add-to-roll $(vcli keys show voter -a)
 
 echo "12345678" | pbbd gentx --details val --name admin
 pbbd collect-gentxs